@@ -0,0 +1,54 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceKafkaExpiredTopics() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceKafkaExpiredTopicsRead,
+
+		Schema: map[string]*schema.Schema{
+			"names": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "names of the topics whose expiry has passed",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"expires_at": &schema.Schema{
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "RFC3339 expiry time of each expired topic",
+			},
+		},
+	}
+}
+
+func dataSourceKafkaExpiredTopicsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+
+	expiries, err := client.listTopicExpiries()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	names := []string{}
+	expiresAt := make(map[string]string)
+	for name, t := range expiries {
+		if now.After(t) {
+			names = append(names, name)
+			expiresAt[name] = t.Format(time.RFC3339)
+		}
+	}
+	sort.Strings(names)
+
+	d.SetId(now.UTC().Format(time.RFC3339))
+	d.Set("names", names)
+	d.Set("expires_at", expiresAt)
+
+	return nil
+}
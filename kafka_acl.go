@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+const (
+	aclPatternLiteral  = "literal"
+	aclPatternPrefixed = "prefixed"
+)
+
+// aclResourceFlags maps ACL resource types to the kafka-acls option naming
+// the resource.
+var aclResourceFlags = map[string]string{
+	"Topic":           "--topic",
+	"Group":           "--group",
+	"Cluster":         "--cluster",
+	"TransactionalId": "--transactional-id",
+	"DelegationToken": "--delegation-token",
+}
+
+// aclClusterName is the only resource name the Cluster resource type has.
+const aclClusterName = "kafka-cluster"
+
+// KafkaAclClient manages authorizer ACLs with kafka-acls, through the
+// brokers when bootstrap servers are known and through ZooKeeper otherwise.
+type KafkaAclClient struct {
+	Zookeeper        string
+	BootstrapServers string
+	AclScript        string
+}
+
+// AclEntry is one access control entry: who may or may not do what, from
+// where.
+type AclEntry struct {
+	Principal      string
+	Host           string
+	Operation      string
+	PermissionType string
+}
+
+// KafkaAcl is an access control entry bound to a resource pattern.
+type KafkaAcl struct {
+	AclEntry
+	ResourceType string
+	ResourceName string
+	PatternType  string
+}
+
+func (client *KafkaAclClient) createAcl(acl *KafkaAcl) error {
+	log.Printf("[INFO] Adding ACL %s", acl)
+	return client.execAclCommand(acl.commandArgs("--add"))
+}
+
+func (client *KafkaAclClient) deleteAcl(acl *KafkaAcl) error {
+	log.Printf("[INFO] Removing ACL %s", acl)
+	return client.execAclCommand(append(acl.commandArgs("--remove"), "--force"))
+}
+
+// listAcls returns the entries on the resource pattern of an ACL.
+func (client *KafkaAclClient) listAcls(acl *KafkaAcl) ([]AclEntry, error) {
+	if client.AclScript == "" {
+		return nil, fmt.Errorf("Unable to find kafka-acls to manage ACLs")
+	}
+
+	params := append(client.connectionArgs(), "--list")
+	params = append(params, acl.resourceArgs()...)
+	cmd := exec.Command(client.AclScript, params...)
+
+	out, err := cmd.CombinedOutput()
+	if kafkaError := readError(string(out)); kafkaError != nil {
+		return nil, kafkaError
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to execute command '%v': %s: %s", cmd.Args, err, strings.TrimSpace(string(out)))
+	}
+
+	return readAclEntries(string(out)), nil
+}
+
+func (client *KafkaAclClient) connectionArgs() []string {
+	if client.BootstrapServers != "" {
+		return []string{"--bootstrap-server", client.BootstrapServers}
+	}
+	return []string{"--authorizer-properties", "zookeeper.connect=" + client.Zookeeper}
+}
+
+func (client *KafkaAclClient) execAclCommand(args []string) error {
+	if client.AclScript == "" {
+		return fmt.Errorf("Unable to find kafka-acls to manage ACLs")
+	}
+	return execCombinedCommand(exec.Command(client.AclScript, append(client.connectionArgs(), args...)...))
+}
+
+// commandArgs builds the kafka-acls options adding or removing the ACL.
+func (acl *KafkaAcl) commandArgs(action string) []string {
+	permission := "allow"
+	if strings.EqualFold(acl.PermissionType, "Deny") {
+		permission = "deny"
+	}
+
+	args := []string{
+		action,
+		"--" + permission + "-principal", acl.Principal,
+		"--" + permission + "-host", acl.Host,
+		"--operation", acl.Operation,
+	}
+	return append(args, acl.resourceArgs()...)
+}
+
+// resourceArgs builds the kafka-acls options naming the resource pattern.
+// The pattern type is left out for literal patterns so that tools older
+// than prefixed ACLs keep working.
+func (acl *KafkaAcl) resourceArgs() []string {
+	var args []string
+	if acl.ResourceType == "Cluster" {
+		args = []string{"--cluster"}
+	} else {
+		args = []string{aclResourceFlags[acl.ResourceType], acl.ResourceName}
+	}
+	if acl.PatternType != "" && acl.PatternType != aclPatternLiteral {
+		args = append(args, "--resource-pattern-type", acl.PatternType)
+	}
+	return args
+}
+
+func (acl *KafkaAcl) String() string {
+	return fmt.Sprintf("%s %s %s from %s on %s %s %s",
+		acl.Principal, acl.PermissionType, acl.Operation, acl.Host, acl.PatternType, acl.ResourceType, acl.ResourceName)
+}
+
+// id joins the fields of an ACL into a resource ID, with the resource name
+// last since it is the field most likely to contain odd characters.
+func (acl *KafkaAcl) id() string {
+	return strings.Join([]string{
+		acl.Principal, acl.Host, acl.Operation, acl.PermissionType,
+		acl.ResourceType, acl.PatternType, acl.ResourceName,
+	}, "|")
+}
+
+func parseAclID(id string) (*KafkaAcl, error) {
+	parts := strings.SplitN(id, "|", 7)
+	if len(parts) != 7 {
+		return nil, fmt.Errorf("Invalid ACL ID '%s', expected principal|host|operation|permission_type|resource_type|pattern_type|resource_name", id)
+	}
+	return &KafkaAcl{
+		AclEntry: AclEntry{
+			Principal:      parts[0],
+			Host:           parts[1],
+			Operation:      parts[2],
+			PermissionType: parts[3],
+		},
+		ResourceType: parts[4],
+		PatternType:  parts[5],
+		ResourceName: parts[6],
+	}, nil
+}
+
+// matches tells whether two entries are the same, ignoring the differences
+// in spelling between kafka-acls versions: "Read" against "READ", or
+// "DescribeConfigs" against "DESCRIBE_CONFIGS".
+func (e AclEntry) matches(o AclEntry) bool {
+	return e.Principal == o.Principal &&
+		e.Host == o.Host &&
+		aclToken(e.Operation) == aclToken(o.Operation) &&
+		aclToken(e.PermissionType) == aclToken(o.PermissionType)
+}
+
+func aclToken(s string) string {
+	return strings.ToLower(strings.Replace(s, "_", "", -1))
+}
+
+// readAclEntries parses the entries kafka-acls --list prints, both in the
+// format of Kafka 2.0 and later:
+//
+//	(principal=User:alice, host=*, operation=READ, permissionType=ALLOW)
+//
+// and in the older one:
+//
+//	User:alice has Allow permission for operations: Read from hosts: *
+func readAclEntries(txt string) []AclEntry {
+	newR := regexp.MustCompile(`^\(principal=(.+), host=(.+?), operation=(\w+), permissionType=(\w+)\)$`)
+	oldR := regexp.MustCompile(`^(.+) has (\w+) permission for operations: (\w+) from hosts: (.+)$`)
+
+	var entries []AclEntry
+	for _, line := range strings.Split(txt, "\n") {
+		line = strings.TrimSpace(line)
+
+		if m := newR.FindStringSubmatch(line); m != nil {
+			entries = append(entries, AclEntry{Principal: m[1], Host: m[2], Operation: m[3], PermissionType: m[4]})
+		} else if m := oldR.FindStringSubmatch(line); m != nil {
+			entries = append(entries, AclEntry{Principal: m[1], Host: m[4], Operation: m[3], PermissionType: m[2]})
+		}
+	}
+	return entries
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadAclEntries(t *testing.T) {
+	newFormat := `Current ACLs for resource ` + "`ResourcePattern(resourceType=TOPIC, name=orders, patternType=LITERAL)`" + `: 
+ 	(principal=User:alice, host=*, operation=READ, permissionType=ALLOW)
+	(principal=User:CN=bob, OU=ops, host=10.0.0.1, operation=DESCRIBE_CONFIGS, permissionType=DENY) 
+`
+	oldFormat := `Current ACLs for resource ` + "`Topic:orders`" + `: 
+ 	User:alice has Allow permission for operations: Read from hosts: *
+	User:CN=bob, OU=ops has Deny permission for operations: DescribeConfigs from hosts: 10.0.0.1 
+`
+	expected := []AclEntry{
+		{Principal: "User:alice", Host: "*", Operation: "Read", PermissionType: "Allow"},
+		{Principal: "User:CN=bob, OU=ops", Host: "10.0.0.1", Operation: "DescribeConfigs", PermissionType: "Deny"},
+	}
+
+	for _, txt := range []string{newFormat, oldFormat} {
+		entries := readAclEntries(txt)
+		if len(entries) != len(expected) {
+			t.Fatalf("expected %d entries, got %v", len(expected), entries)
+		}
+		for i, e := range entries {
+			if !e.matches(expected[i]) {
+				t.Errorf("entry %d: expected %v, got %v", i, expected[i], e)
+			}
+		}
+	}
+
+	if entries := readAclEntries("Current ACLs for resource `Topic:orders`: \n"); len(entries) != 0 {
+		t.Errorf("expected no entries, got %v", entries)
+	}
+}
+
+func TestKafkaAcl_id(t *testing.T) {
+	acl := &KafkaAcl{
+		AclEntry:     AclEntry{Principal: "User:alice", Host: "*", Operation: "Write", PermissionType: "Allow"},
+		ResourceType: "Topic",
+		ResourceName: "orders.",
+		PatternType:  aclPatternPrefixed,
+	}
+
+	id := acl.id()
+	if id != "User:alice|*|Write|Allow|Topic|prefixed|orders." {
+		t.Errorf("unexpected ID %s", id)
+	}
+
+	parsed, err := parseAclID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, acl) {
+		t.Errorf("expected %v, got %v", acl, parsed)
+	}
+
+	if _, err := parseAclID("User:alice|*|Write"); err == nil {
+		t.Errorf("expected an error for a short ID")
+	}
+}
+
+func TestKafkaAcl_commandArgs(t *testing.T) {
+	acl := &KafkaAcl{
+		AclEntry:     AclEntry{Principal: "User:alice", Host: "*", Operation: "Read", PermissionType: "Deny"},
+		ResourceType: "Group",
+		ResourceName: "billing",
+		PatternType:  aclPatternLiteral,
+	}
+	assertStrings(t, "add args", acl.commandArgs("--add"), []string{
+		"--add", "--deny-principal", "User:alice", "--deny-host", "*", "--operation", "Read", "--group", "billing",
+	})
+
+	acl.PatternType = aclPatternPrefixed
+	acl.ResourceType = "Cluster"
+	acl.ResourceName = aclClusterName
+	assertStrings(t, "cluster args", acl.resourceArgs(), []string{"--cluster", "--resource-pattern-type", "prefixed"})
+}
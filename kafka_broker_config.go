@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Dynamic update modes of broker configs, as the Kafka documentation lists
+// them.
+const (
+	brokerConfigReadOnly    = "read-only"
+	brokerConfigPerBroker   = "per-broker"
+	brokerConfigClusterWide = "cluster-wide"
+)
+
+// brokerConfigModes lists the dynamic update mode of the broker configs
+// most often changed. Per-broker configs can only be set on a broker,
+// cluster-wide ones also as the cluster default, and read-only ones need a
+// change to server.properties and a restart. Configs missing from the list
+// are left to Kafka to accept or refuse.
+var brokerConfigModes = map[string]string{
+	"broker.id":                                brokerConfigReadOnly,
+	"broker.rack":                              brokerConfigReadOnly,
+	"log.dirs":                                 brokerConfigReadOnly,
+	"log.dir":                                  brokerConfigReadOnly,
+	"zookeeper.connect":                        brokerConfigReadOnly,
+	"zookeeper.session.timeout.ms":             brokerConfigReadOnly,
+	"num.partitions":                           brokerConfigReadOnly,
+	"default.replication.factor":               brokerConfigReadOnly,
+	"auto.create.topics.enable":                brokerConfigReadOnly,
+	"auto.leader.rebalance.enable":             brokerConfigReadOnly,
+	"delete.topic.enable":                      brokerConfigReadOnly,
+	"controlled.shutdown.enable":               brokerConfigReadOnly,
+	"inter.broker.protocol.version":            brokerConfigReadOnly,
+	"log.message.format.version":               brokerConfigReadOnly,
+	"offsets.topic.replication.factor":         brokerConfigReadOnly,
+	"transaction.state.log.replication.factor": brokerConfigReadOnly,
+	"transaction.state.log.min.isr":            brokerConfigReadOnly,
+
+	"listeners":                            brokerConfigPerBroker,
+	"advertised.listeners":                 brokerConfigPerBroker,
+	"listener.security.protocol.map":       brokerConfigPerBroker,
+	"ssl.keystore.type":                    brokerConfigPerBroker,
+	"ssl.keystore.location":                brokerConfigPerBroker,
+	"ssl.keystore.password":                brokerConfigPerBroker,
+	"ssl.key.password":                     brokerConfigPerBroker,
+	"ssl.truststore.type":                  brokerConfigPerBroker,
+	"ssl.truststore.location":              brokerConfigPerBroker,
+	"ssl.truststore.password":              brokerConfigPerBroker,
+	"sasl.enabled.mechanisms":              brokerConfigPerBroker,
+	"sasl.kerberos.service.name":           brokerConfigPerBroker,
+	"sasl.mechanism.inter.broker.protocol": brokerConfigPerBroker,
+
+	"background.threads":                             brokerConfigClusterWide,
+	"compression.type":                               brokerConfigClusterWide,
+	"follower.replication.throttled.rate":            brokerConfigClusterWide,
+	"leader.replication.throttled.rate":              brokerConfigClusterWide,
+	"log.cleaner.backoff.ms":                         brokerConfigClusterWide,
+	"log.cleaner.dedupe.buffer.size":                 brokerConfigClusterWide,
+	"log.cleaner.delete.retention.ms":                brokerConfigClusterWide,
+	"log.cleaner.io.buffer.load.factor":              brokerConfigClusterWide,
+	"log.cleaner.io.buffer.size":                     brokerConfigClusterWide,
+	"log.cleaner.io.max.bytes.per.second":            brokerConfigClusterWide,
+	"log.cleaner.min.cleanable.ratio":                brokerConfigClusterWide,
+	"log.cleaner.min.compaction.lag.ms":              brokerConfigClusterWide,
+	"log.cleaner.threads":                            brokerConfigClusterWide,
+	"log.cleanup.policy":                             brokerConfigClusterWide,
+	"log.flush.interval.messages":                    brokerConfigClusterWide,
+	"log.flush.interval.ms":                          brokerConfigClusterWide,
+	"log.index.interval.bytes":                       brokerConfigClusterWide,
+	"log.index.size.max.bytes":                       brokerConfigClusterWide,
+	"log.message.downconversion.enable":              brokerConfigClusterWide,
+	"log.message.timestamp.difference.max.ms":        brokerConfigClusterWide,
+	"log.message.timestamp.type":                     brokerConfigClusterWide,
+	"log.preallocate":                                brokerConfigClusterWide,
+	"log.retention.bytes":                            brokerConfigClusterWide,
+	"log.retention.ms":                               brokerConfigClusterWide,
+	"log.roll.jitter.ms":                             brokerConfigClusterWide,
+	"log.roll.ms":                                    brokerConfigClusterWide,
+	"log.segment.bytes":                              brokerConfigClusterWide,
+	"log.segment.delete.delay.ms":                    brokerConfigClusterWide,
+	"max.connections.per.ip":                         brokerConfigClusterWide,
+	"max.connections.per.ip.overrides":               brokerConfigClusterWide,
+	"message.max.bytes":                              brokerConfigClusterWide,
+	"metric.reporters":                               brokerConfigClusterWide,
+	"min.insync.replicas":                            brokerConfigClusterWide,
+	"num.io.threads":                                 brokerConfigClusterWide,
+	"num.network.threads":                            brokerConfigClusterWide,
+	"num.recovery.threads.per.data.dir":              brokerConfigClusterWide,
+	"num.replica.fetchers":                           brokerConfigClusterWide,
+	"replica.alter.log.dirs.io.max.bytes.per.second": brokerConfigClusterWide,
+	"unclean.leader.election.enable":                 brokerConfigClusterWide,
+}
+
+// brokerConfigMode returns the dynamic update mode of a broker config, or ""
+// when it is not known. Listener-prefixed configs are per-broker.
+func brokerConfigMode(key string) string {
+	if strings.HasPrefix(key, "listener.name.") {
+		return brokerConfigPerBroker
+	}
+	return brokerConfigModes[key]
+}
+
+// checkBrokerConfig refuses configs that cannot be changed dynamically on
+// the entity: read-only ones anywhere, per-broker ones as cluster default.
+func checkBrokerConfig(conf map[string]string, clusterDefault bool) error {
+	for _, k := range sortedKeys(conf) {
+		switch brokerConfigMode(k) {
+		case brokerConfigReadOnly:
+			return fmt.Errorf("%s is a read-only broker config, it can only be changed in server.properties followed by a restart", k)
+		case brokerConfigPerBroker:
+			if clusterDefault {
+				return fmt.Errorf("%s is a per-broker config, it needs a broker_id", k)
+			}
+		}
+	}
+	return nil
+}
+
+func brokerEntity(brokerID string) configEntity {
+	if brokerID == "" {
+		brokerID = entityDefault
+	}
+	return configEntity{{Type: "brokers", Name: brokerID}}
+}
+
+// dynamicBrokerConfig keeps the entries set dynamically on the entity out of
+// those kafka-configs describes through the brokers.
+func dynamicBrokerConfig(entity configEntity, entries map[string]ConfigEntry) map[string]string {
+	source := configSourceDynamicBroker
+	if entity[0].Name == entityDefault {
+		source = configSourceDynamicDefaultBroker
+	}
+
+	conf := make(map[string]string)
+	for k, e := range entries {
+		if e.Source == source {
+			conf[k] = e.Value
+		}
+	}
+	return conf
+}
+
+// describeStaticBrokerConfig reads the configs a broker took from its
+// server.properties. It needs bootstrap servers and a kafka-configs recent
+// enough to support --all.
+func (client *KafkaManagingClient) describeStaticBrokerConfig(brokerID string) (map[string]string, error) {
+	cmd := exec.Command(
+		client.ConfigScript,
+		"--bootstrap-server", client.BootstrapServers,
+		"--entity-type", "brokers",
+		"--entity-name", brokerID,
+		"--describe", "--all")
+
+	out, err := cmd.Output()
+	if err != nil {
+		kafkaError := readError(string(out))
+		if kafkaError != nil {
+			return nil, kafkaError
+		}
+		return nil, err
+	}
+
+	conf := make(map[string]string)
+	for k, e := range readConfigEntries(string(out)) {
+		if e.Source == configSourceStaticBroker && !e.Sensitive {
+			conf[k] = e.Value
+		}
+	}
+	return conf, nil
+}
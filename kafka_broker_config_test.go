@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestCheckBrokerConfig(t *testing.T) {
+	if err := checkBrokerConfig(map[string]string{"log.cleaner.threads": "2", "some.plugin.setting": "x"}, true); err != nil {
+		t.Errorf("unexpected error %s", err)
+	}
+	if err := checkBrokerConfig(map[string]string{"listener.name.internal.ssl.keystore.location": "/ks"}, false); err != nil {
+		t.Errorf("unexpected error %s", err)
+	}
+
+	if err := checkBrokerConfig(map[string]string{"listener.name.internal.ssl.keystore.location": "/ks"}, true); err == nil {
+		t.Errorf("expected per-broker config to be refused as cluster default")
+	}
+	if err := checkBrokerConfig(map[string]string{"num.partitions": "3"}, false); err == nil {
+		t.Errorf("expected read-only config to be refused")
+	}
+}
+
+func TestDynamicBrokerConfig(t *testing.T) {
+	out := `Dynamic configs for broker 0 are:
+  log.cleaner.threads=2 sensitive=false synonyms={DYNAMIC_BROKER_CONFIG:log.cleaner.threads=2, DYNAMIC_DEFAULT_BROKER_CONFIG:log.cleaner.threads=3, DEFAULT_CONFIG:log.cleaner.threads=1}
+  ssl.keystore.password=null sensitive=true synonyms={DYNAMIC_BROKER_CONFIG:ssl.keystore.password=null}
+  message.max.bytes=2000000 sensitive=false synonyms={DYNAMIC_DEFAULT_BROKER_CONFIG:message.max.bytes=2000000}
+`
+	entries := readConfigEntries(out)
+
+	assertStringMap(t, "broker", dynamicBrokerConfig(brokerEntity("0"), entries),
+		map[string]string{"log.cleaner.threads": "2", "ssl.keystore.password": "null"})
+	assertStringMap(t, "cluster default", dynamicBrokerConfig(brokerEntity(""), entries),
+		map[string]string{"message.max.bytes": "2000000"})
+}
@@ -11,9 +11,24 @@ import (
 
 // KafkaManagingClient does client stuff.
 type KafkaManagingClient struct {
-	Zookeeper    string
-	TopicScript  string
-	ConfigScript string
+	Zookeeper            string
+	BootstrapServers     string
+	TopicScript          string
+	ConfigScript         string
+	ZookeeperShellScript string
+	// LeaderElectionScript and PreferredReplicaElectionScript are optional;
+	// which one is found depends on the Kafka version.
+	LeaderElectionScript           string
+	PreferredReplicaElectionScript string
+	ConsumerGroupsScript           string
+	ReassignPartitionsScript       string
+	ConsoleProducerScript          string
+	TopicDefaults                  TopicDefaults
+	TopicNamePolicy                TopicNamePolicy
+	TopicLimits                    TopicLimits
+	Acls                           *KafkaAclClient
+	SchemaRegistry                 *SchemaRegistryClient
+	Connect                        *KafkaConnectClient
 }
 
 func (client *KafkaManagingClient) alterTopicPartitions(name string, partitions int) error {
@@ -27,21 +42,8 @@ func (client *KafkaManagingClient) alterTopicPartitions(name string, partitions
 	return execKafkaCommand(cmd, "Adding partitions succeeded")
 }
 
-func (client *KafkaManagingClient) alterTopicConfig(name string, conf *KafkaTopicInfo) error {
-	var params = []string{
-		"--zookeeper", client.Zookeeper,
-		"--entity-type", "topics",
-		"--entity-name", name,
-		"--alter",
-	}
-
-	confOpts := conf.alterTopicConfigOpts()
-	params = append(params, confOpts...)
-
-	log.Printf("Will update configs for topic %s: %v", name, confOpts)
-	cmd := exec.Command(client.ConfigScript, params...)
-
-	return execKafkaCommand(cmd, "Completed Updating config for entity: topic")
+func (client *KafkaManagingClient) alterTopicConfig(name string, mods ConfMods) error {
+	return client.alterEntityConfig(topicEntity(name), mods)
 }
 
 func (client *KafkaManagingClient) deleteTopic(name string) error {
@@ -79,13 +81,49 @@ func (client *KafkaManagingClient) createTopic(name string, conf *KafkaTopicInfo
 	}
 
 	strOut := strings.TrimSpace(string(out))
-	if strOut == fmt.Sprintf("Created topic \"%s\".", name) {
+	for _, w := range readWarnings(strOut) {
+		log.Printf("[WARN] Kafka topic '%s': %s", name, w)
+	}
+
+	createdR := regexp.MustCompile(fmt.Sprintf("(?m:^Created topic \"?%s\"?\\.$)", regexp.QuoteMeta(name)))
+	if createdR.MatchString(strOut) {
 		return nil
 	}
 
 	return fmt.Errorf("Unable to parse results from kafka, there is maybe something wrong: %s", strOut)
 }
 
+func (client *KafkaManagingClient) listTopics() ([]string, error) {
+	cmd := exec.Command(client.TopicScript, "--zookeeper", client.Zookeeper, "--list")
+
+	out, err := cmd.Output()
+	if err != nil {
+		kafkaError := readError(string(out))
+		if kafkaError != nil {
+			return nil, kafkaError
+		}
+		return nil, err
+	}
+
+	return readTopicList(string(out)), nil
+}
+
+// clusterPartitionCount returns the number of partitions of all topics.
+func (client *KafkaManagingClient) clusterPartitionCount() (int, error) {
+	cmd := exec.Command(client.TopicScript, "--zookeeper", client.Zookeeper, "--describe")
+
+	out, err := cmd.Output()
+	if err != nil {
+		kafkaError := readError(string(out))
+		if kafkaError != nil {
+			return 0, kafkaError
+		}
+		return 0, err
+	}
+
+	return readTotalPartitions(string(out))
+}
+
 func (client *KafkaManagingClient) describeTopic(name string) (*KafkaTopicInfo, error) {
 	cmd := exec.Command(client.TopicScript, "--zookeeper", client.Zookeeper, "--describe", "--topic", name)
 
@@ -98,11 +136,47 @@ func (client *KafkaManagingClient) describeTopic(name string) (*KafkaTopicInfo,
 
 	//does not exist
 	if strOut == "" {
-		fmt.Printf("Topic not found {}", name)
+		log.Printf("[DEBUG] Topic '%s' not found", name)
 		return nil, nil
 	}
 
-	return readTopicInfo(strOut)
+	info, err := readTopicInfo(strOut)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.BootstrapServers != "" {
+		entries, cErr := client.describeTopicConfig(name)
+		if cErr != nil {
+			log.Printf("[WARN] Unable to read effective config for topic '%s', only overrides will be known: %v", name, cErr)
+		} else {
+			info.EffectiveConfig = entries
+		}
+	}
+
+	return info, nil
+}
+
+// describeTopicConfig reads every config value in effect for a topic along
+// with its source. It needs a kafka-configs recent enough to support --all.
+func (client *KafkaManagingClient) describeTopicConfig(name string) (map[string]ConfigEntry, error) {
+	cmd := exec.Command(
+		client.ConfigScript,
+		"--bootstrap-server", client.BootstrapServers,
+		"--entity-type", "topics",
+		"--entity-name", name,
+		"--describe", "--all")
+
+	out, err := cmd.Output()
+	if err != nil {
+		kafkaError := readError(string(out))
+		if kafkaError != nil {
+			return nil, kafkaError
+		}
+		return nil, err
+	}
+
+	return readConfigEntries(string(out)), nil
 }
 
 func readError(txt string) error {
@@ -115,8 +189,49 @@ func readError(txt string) error {
 	return fmt.Errorf("%s", err)
 }
 
+// readWarnings returns the WARNING lines Kafka tools print alongside their
+// normal output, such as the one about periods and underscores in topic
+// names.
+func readWarnings(txt string) []string {
+	warningR, _ := regexp.Compile("(?m:^WARNING: .+)")
+	var warnings []string
+	for _, w := range warningR.FindAllString(txt, -1) {
+		warnings = append(warnings, strings.TrimSpace(strings.TrimPrefix(w, "WARNING: ")))
+	}
+	return warnings
+}
+
+// readTopicList parses kafka-topics --list, which prints one topic per line,
+// possibly followed by " - marked for deletion".
+func readTopicList(txt string) []string {
+	topics := []string{}
+	for _, line := range strings.Split(txt, "\n") {
+		line = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), "- marked for deletion"))
+		if line == "" || strings.HasPrefix(line, "WARNING") {
+			continue
+		}
+		topics = append(topics, line)
+	}
+	return topics
+}
+
+// readTotalPartitions sums the partition counts of every topic listed by
+// kafka-topics --describe.
+func readTotalPartitions(txt string) (int, error) {
+	countR, _ := regexp.Compile("PartitionCount:\\s*(\\d+)")
+	total := 0
+	for _, m := range countR.FindAllStringSubmatch(txt, -1) {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return 0, fmt.Errorf("Unable to read topic's partition count: %s", err)
+		}
+		total += n
+	}
+	return total, nil
+}
+
 func readTopicInfo(txt string) (*KafkaTopicInfo, error) {
-	partsR, _ := regexp.Compile("PartitionCount:(\\d+).+ReplicationFactor:(\\d+).+Configs:\\s*([^\\s]+)?")
+	partsR, _ := regexp.Compile("PartitionCount:\\s*(\\d+).+ReplicationFactor:\\s*(\\d+).+Configs:\\s*([^\\s]+)?")
 	pRes := partsR.FindStringSubmatch(txt)
 	if len(pRes) != 4 {
 		return nil, fmt.Errorf("Unable to determine topic's partitions count (Unexpected format)")
@@ -135,12 +250,24 @@ func readTopicInfo(txt string) (*KafkaTopicInfo, error) {
 	// Read config options
 	confOpts := make(map[string]string)
 
+	// List values such as cleanup.policy=compact,delete share the separator,
+	// so a piece without "=" continues the previous value.
+	lastKey := ""
 	for _, e := range strings.Split(pRes[3], ",") {
 		if !strings.Contains(e, "=") {
+			if lastKey != "" {
+				confOpts[lastKey] += "," + e
+			}
 			continue
 		}
 		ps := strings.SplitN(e, "=", 2)
 		confOpts[ps[0]] = ps[1]
+		lastKey = ps[0]
+	}
+
+	partitions, err := readPartitions(txt)
+	if err != nil {
+		return nil, err
 	}
 
 	info := &KafkaTopicInfo{
@@ -151,11 +278,91 @@ func readTopicInfo(txt string) (*KafkaTopicInfo, error) {
 		RetentionMs:       getOrDefaultInt(confOpts, "retention.ms", -1),
 		SegmentMs:         getOrDefaultInt(confOpts, "segment.ms", -1),
 		SegmentBytes:      getOrDefaultInt(confOpts, "segment.bytes", -1),
+		Partitions:        partitions,
+		Config:            confOpts,
 	}
 
 	return info, nil
 }
 
+// readConfigEntries parses the output of kafka-configs --describe when run
+// against the brokers, where each config is listed on its own line as
+// "key=value sensitive=false synonyms={SOURCE:key=value, ...}".
+func readConfigEntries(txt string) map[string]ConfigEntry {
+	entryR, _ := regexp.Compile("(?m:^\\s*([^\\s=]+)=(.*) sensitive=(true|false) synonyms=\\{(.*)\\}\\s*$)")
+	entries := make(map[string]ConfigEntry)
+
+	for _, m := range entryR.FindAllStringSubmatch(txt, -1) {
+		source := configSourceDefault
+		if m[4] != "" {
+			source = strings.SplitN(m[4], ":", 2)[0]
+		}
+		entries[m[1]] = ConfigEntry{
+			Value:     m[2],
+			Source:    source,
+			Sensitive: m[3] == "true",
+		}
+	}
+
+	return entries
+}
+
+// readPartitions parses the per-partition lines of kafka-topics --describe.
+// A partition without a leader is reported with Leader -1 or "none"
+// depending on the Kafka version; both are read as -1.
+func readPartitions(txt string) ([]PartitionInfo, error) {
+	partR, _ := regexp.Compile("Partition:\\s*(\\d+)\\s+Leader:\\s*(-?\\d+|none)\\s+Replicas:\\s*([\\d,]*)\\s+Isr:\\s*([\\d,]*)")
+	partitions := []PartitionInfo{}
+
+	for _, m := range partR.FindAllStringSubmatch(txt, -1) {
+		id, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read partition id: %s", err)
+		}
+
+		leader := -1
+		if m[2] != "none" {
+			if leader, err = strconv.Atoi(m[2]); err != nil {
+				return nil, fmt.Errorf("Unable to read leader of partition %d: %s", id, err.Error())
+			}
+		}
+
+		replicas, err := readBrokerList(m[3])
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read replicas of partition %d: %s", id, err.Error())
+		}
+
+		isr, err := readBrokerList(m[4])
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read ISR of partition %d: %s", id, err.Error())
+		}
+
+		partitions = append(partitions, PartitionInfo{
+			ID:       id,
+			Leader:   leader,
+			Replicas: replicas,
+			Isr:      isr,
+		})
+	}
+
+	return partitions, nil
+}
+
+func readBrokerList(txt string) ([]int, error) {
+	brokers := []int{}
+	for _, b := range strings.Split(txt, ",") {
+		if b == "" {
+			continue
+		}
+		id, err := strconv.Atoi(b)
+		if err != nil {
+			return nil, err
+		}
+		brokers = append(brokers, id)
+	}
+	return brokers, nil
+}
+
 func execKafkaCommand(cmd *exec.Cmd, successIfPresent string) error {
 	out, err := cmd.Output()
 	if err != nil {
@@ -174,6 +381,21 @@ func execKafkaCommand(cmd *exec.Cmd, successIfPresent string) error {
 	return fmt.Errorf("Unable to execute command '%v': %s", cmd.Args, strOut)
 }
 
+// execCombinedCommand runs a tool that reports errors on either output
+// stream and whose success output varies between Kafka versions.
+func execCombinedCommand(cmd *exec.Cmd) error {
+	log.Printf("[DEBUG] Will execute %v", cmd.Args)
+
+	out, err := cmd.CombinedOutput()
+	if kafkaError := readError(string(out)); kafkaError != nil {
+		return kafkaError
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to execute command '%v': %s: %s", cmd.Args, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
 func getOrDefaultStr(m map[string]string, key string, def string) string {
 	if v, ok := m[key]; ok {
 		return v
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -19,8 +20,22 @@ Topic: file-imported	Partition: 9	Leader: -1	Replicas: 0	Isr:
 Topic: file-imported	Partition: 10	Leader: -1	Replicas: 0	Isr:
 Topic: file-imported	Partition: 11	Leader: -1	Replicas: 0	Isr:`
 
-	shortDescribeResponse  = "Topic:file-imported	PartitionCount:12	ReplicationFactor:3	Configs:retention.ms=1457999337,cleanup.policy=compact,segment.ms=86400000,segment.bytes=10000"
-	shortDescribeResponse2 = "Topic:file-imported	PartitionCount:12	ReplicationFactor:3	Configs:retention.bytes=1023"
+	shortDescribeResponse     = "Topic:file-imported	PartitionCount:12	ReplicationFactor:3	Configs:retention.ms=1457999337,cleanup.policy=compact,segment.ms=86400000,segment.bytes=10000"
+	shortDescribeResponse2    = "Topic:file-imported	PartitionCount:12	ReplicationFactor:3	Configs:retention.bytes=1023"
+	listDescribeResponse      = "Topic:file-imported	PartitionCount:12	ReplicationFactor:3	Configs:cleanup.policy=compact,delete,retention.ms=1000"
+	unmanagedDescribeResponse = "Topic:file-imported	PartitionCount:12	ReplicationFactor:3	Configs:retention.ms=1000,min.insync.replicas=2,compression.type=lz4"
+
+	partitionsDescribeResponse = `Topic: orders	TopicId: 3vY5mzfFQxCXl1Ll6K4L1Q	PartitionCount: 3	ReplicationFactor: 2	Configs: min.insync.replicas=2
+	Topic: orders	Partition: 0	Leader: 1	Replicas: 1,2	Isr: 1,2
+	Topic: orders	Partition: 1	Leader: 2	Replicas: 2,3	Isr: 2
+	Topic: orders	Partition: 2	Leader: none	Replicas: 3,1	Isr: `
+
+	allConfigsResponse = `All configs for topic file-imported are:
+  compression.type=producer sensitive=false synonyms={DEFAULT_CONFIG:compression.type=producer}
+  leader.replication.throttled.replicas= sensitive=false synonyms={}
+  retention.ms=604800000 sensitive=false synonyms={DEFAULT_CONFIG:log.retention.hours=168}
+  cleanup.policy=compact sensitive=false synonyms={DYNAMIC_TOPIC_CONFIG:cleanup.policy=compact, DEFAULT_CONFIG:log.cleanup.policy=delete}
+  ssl.key.password=null sensitive=true synonyms={}`
 
 	emptyDescribeResponse = ""
 
@@ -98,12 +113,109 @@ func TestKafkaManagingClient_shortTopicInfo(t *testing.T) {
 	assertInt64(t, "SegmentMs", res.SegmentMs, -1)
 }
 
+func TestKafkaManagingClient_partitionsTopicInfo(t *testing.T) {
+	res, err := readTopicInfo(partitionsDescribeResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "PartitionsCount", res.PartitionsCount, 3)
+	assertInt(t, "ReplicationFactor", res.ReplicationFactor, 2)
+	assertInt(t, "len(Partitions)", len(res.Partitions), 3)
+
+	p := res.Partitions[1]
+	assertInt(t, "Partitions[1].ID", p.ID, 1)
+	assertInt(t, "Partitions[1].Leader", p.Leader, 2)
+	assertInts(t, "Partitions[1].Replicas", p.Replicas, []int{2, 3})
+	assertInts(t, "Partitions[1].Isr", p.Isr, []int{2})
+
+	p = res.Partitions[2]
+	assertInt(t, "Partitions[2].Leader", p.Leader, -1)
+	assertInts(t, "Partitions[2].Isr", p.Isr, []int{})
+	if !p.offline() {
+		t.Errorf("expected partition 2 to be offline")
+	}
+
+	h := res.health()
+	assertInt(t, "UnderReplicated", h.UnderReplicated, 2)
+	assertInt(t, "Offline", h.Offline, 1)
+	assertInt(t, "IsrBelowMin", h.IsrBelowMin, 2)
+
+	res, err = readTopicInfo(validDescribeResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "len(Partitions)", len(res.Partitions), 12)
+	assertInts(t, "Partitions[11].Replicas", res.Partitions[11].Replicas, []int{0})
+}
+
+func TestKafkaManagingClient_readTotalPartitions(t *testing.T) {
+	total, err := readTotalPartitions(validDescribeResponse + "\n" + partitionsDescribeResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "total", total, 15)
+}
+
+func TestKafkaManagingClient_listValueTopicInfo(t *testing.T) {
+	res, err := readTopicInfo(listDescribeResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertString(t, "CleanupPolicy", res.CleanupPolicy, "compact,delete")
+	assertInt64(t, "RetentionMs", res.RetentionMs, 1000)
+}
+
+func TestKafkaManagingClient_unmanagedTopicInfo(t *testing.T) {
+	res, err := readTopicInfo(unmanagedDescribeResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt64(t, "RetentionMs", res.RetentionMs, 1000)
+	assertInt(t, "len(Config)", len(res.Config), 3)
+	assertString(t, "Config[min.insync.replicas]", res.Config["min.insync.replicas"], "2")
+
+	unmanaged := unmanagedConfig(res.Config, map[string]string{"compression.type": "lz4"})
+	assertInt(t, "len(unmanaged)", len(unmanaged), 1)
+	assertString(t, "unmanaged[min.insync.replicas]", unmanaged["min.insync.replicas"], "2")
+	if _, ok := unmanaged["retention.ms"]; ok {
+		t.Errorf("expected retention.ms to be managed")
+	}
+}
+
+func TestKafkaManagingClient_configEntries(t *testing.T) {
+	entries := readConfigEntries(allConfigsResponse)
+	assertInt(t, "len(entries)", len(entries), 5)
+	assertString(t, "compression.type", entries["compression.type"].Value, "producer")
+	assertString(t, "compression.type source", entries["compression.type"].Source, configSourceDefault)
+	assertString(t, "leader.replication.throttled.replicas", entries["leader.replication.throttled.replicas"].Value, "")
+	assertString(t, "cleanup.policy source", entries["cleanup.policy"].Source, configSourceDynamicTopic)
+	if !entries["ssl.key.password"].Sensitive {
+		t.Errorf("expected ssl.key.password to be sensitive")
+	}
+
+	info := &KafkaTopicInfo{
+		Config:          map[string]string{"cleanup.policy": "compact"},
+		EffectiveConfig: entries,
+	}
+	assertString(t, "cleanup.policy state", info.configStateValue("cleanup.policy", "delete", ""), "compact")
+	assertInt(t, "retention.ms state (default written explicitly)", info.configStateInt("retention.ms", 604800000), 604800000)
+	assertInt(t, "retention.ms state (not configured)", info.configStateInt("retention.ms", topicConfigUnset), topicConfigUnset)
+	assertInt(t, "retention.ms state (drifted)", info.configStateInt("retention.ms", 1000), topicConfigUnset)
+	assertInt(t, "retention.ms state (unlimited)", (&KafkaTopicInfo{Config: map[string]string{"retention.ms": "-1"}}).configStateInt("retention.ms", -1), -1)
+}
+
 func assertInt(t *testing.T, name string, value int, expected int) {
 	if expected != value {
 		t.Errorf("expected %s to be %d, but got %d", name, expected, value)
 	}
 }
 
+func assertInts(t *testing.T, name string, value []int, expected []int) {
+	if !reflect.DeepEqual(expected, value) {
+		t.Errorf("expected %s to be %v, but got %v", name, expected, value)
+	}
+}
+
 func assertInt64(t *testing.T, name string, value int64, expected int64) {
 	if expected != value {
 		t.Errorf("expected %s to be %d, but got %d", name, expected, value)
@@ -130,6 +242,17 @@ func TestKafkaManagingClient_invalidResponseTopicInfo(t *testing.T) {
 	}
 }
 
+func TestKafkaManagingClient_readWarnings(t *testing.T) {
+	warnings := readWarnings(errorWithWarnings)
+	assertInt(t, "len(warnings)", len(warnings), 1)
+	assertString(t, "warning", warnings[0], "If partitions are increased for a topic that has a key, the partition logic or ordering of the messages will be affected")
+}
+
+func TestKafkaManagingClient_readTopicList(t *testing.T) {
+	topics := readTopicList("__consumer_offsets\nteam.orders\nold_topic - marked for deletion\n\n")
+	assertStrings(t, "topics", topics, []string{"__consumer_offsets", "team.orders", "old_topic"})
+}
+
 func TestKafkaManagingClient_errorWithWarnings(t *testing.T) {
 	err := readError(errorWithWarnings)
 
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// ConfMods is the set of changes turning one config into another: keys to
+// add or change with their new value, and keys to delete with their old one.
+type ConfMods struct {
+	ConfDeletions map[string]string
+	ConfAdditions map[string]string
+}
+
+func makeConfMods() ConfMods {
+	return ConfMods{
+		ConfDeletions: make(map[string]string),
+		ConfAdditions: make(map[string]string),
+	}
+}
+
+func (conf *ConfMods) empty() bool {
+	return len(conf.ConfAdditions) == 0 && len(conf.ConfDeletions) == 0
+}
+
+// diffConfig compares the desired config against the actual one. Every key
+// whose desired value differs from the actual one is added; a key set in
+// actual but missing from desired is deleted only if owned reports it as
+// managed, so that overrides set by someone else are left alone. A nil owned
+// never deletes anything.
+func diffConfig(desired map[string]string, actual map[string]string, owned func(key string) bool) ConfMods {
+	mods := makeConfMods()
+
+	for k, v := range desired {
+		if av, ok := actual[k]; !ok || av != v {
+			mods.ConfAdditions[k] = v
+		}
+	}
+
+	if owned == nil {
+		return mods
+	}
+
+	for k, v := range actual {
+		if _, ok := desired[k]; !ok && owned(k) {
+			mods.ConfDeletions[k] = v
+		}
+	}
+
+	return mods
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func appendConf(slice []string, name string, value string) []string {
+	return append(slice, "--config", name+"="+value)
+}
+
+// writeCreateConfMods appends the additions as kafka-topics --config options.
+// Deletions are meaningless for a topic that does not exist yet.
+func writeCreateConfMods(slice []string, conf *ConfMods) []string {
+	for _, k := range sortedKeys(conf.ConfAdditions) {
+		slice = appendConf(slice, k, conf.ConfAdditions[k])
+	}
+	return slice
+}
+
+// writeConfMods appends the changes as kafka-configs --add-config and
+// --delete-config options. Values holding commas, such as a list of cleanup
+// policies, are wrapped in brackets as kafka-configs expects.
+func writeConfMods(slice []string, conf *ConfMods) []string {
+	if len(conf.ConfAdditions) > 0 {
+		var pairs []string
+		for _, k := range sortedKeys(conf.ConfAdditions) {
+			v := conf.ConfAdditions[k]
+			if strings.Contains(v, ",") {
+				v = "[" + v + "]"
+			}
+			pairs = append(pairs, k+"="+v)
+		}
+
+		slice = append(slice, "--add-config", strings.Join(pairs, ","))
+	}
+
+	for _, k := range sortedKeys(conf.ConfDeletions) {
+		slice = append(slice, "--delete-config", k)
+	}
+
+	return slice
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffConfig_additionsAndChanges(t *testing.T) {
+	desired := map[string]string{"retention.ms": "1000", "cleanup.policy": "compact"}
+	actual := map[string]string{"retention.ms": "2000"}
+
+	mods := diffConfig(desired, actual, nil)
+	assertStringMap(t, "ConfAdditions", mods.ConfAdditions, desired)
+	assertInt(t, "len(ConfDeletions)", len(mods.ConfDeletions), 0)
+}
+
+func TestDiffConfig_unchanged(t *testing.T) {
+	conf := map[string]string{"retention.ms": "1000"}
+
+	mods := diffConfig(conf, conf, func(string) bool { return true })
+	if !mods.empty() {
+		t.Errorf("expected no changes, got %v", mods)
+	}
+}
+
+func TestDiffConfig_deletesOnlyOwnedKeys(t *testing.T) {
+	desired := map[string]string{"retention.ms": "1000"}
+	actual := map[string]string{"retention.ms": "1000", "segment.ms": "10", "min.insync.replicas": "2"}
+
+	mods := diffConfig(desired, actual, isManagedConfigKey)
+	assertInt(t, "len(ConfAdditions)", len(mods.ConfAdditions), 0)
+	assertStringMap(t, "ConfDeletions", mods.ConfDeletions, map[string]string{"segment.ms": "10"})
+
+	mods = diffConfig(desired, actual, nil)
+	assertInt(t, "len(ConfDeletions) without owner", len(mods.ConfDeletions), 0)
+}
+
+func TestWriteConfMods(t *testing.T) {
+	mods := makeConfMods()
+	mods.ConfAdditions["segment.ms"] = "10"
+	mods.ConfAdditions["cleanup.policy"] = "compact,delete"
+	mods.ConfDeletions["retention.ms"] = "1000"
+	mods.ConfDeletions["min.insync.replicas"] = "2"
+
+	assertStrings(t, "alter options", writeConfMods([]string{}, &mods), []string{
+		"--add-config", "cleanup.policy=[compact,delete],segment.ms=10",
+		"--delete-config", "min.insync.replicas",
+		"--delete-config", "retention.ms",
+	})
+
+	assertStrings(t, "create options", writeCreateConfMods([]string{}, &mods), []string{
+		"--config", "cleanup.policy=compact,delete",
+		"--config", "segment.ms=10",
+	})
+}
+
+func assertStringMap(t *testing.T, name string, value map[string]string, expected map[string]string) {
+	if !reflect.DeepEqual(expected, value) {
+		t.Errorf("expected %s to be %v, but got %v", name, expected, value)
+	}
+}
+
+func assertStrings(t *testing.T, name string, value []string, expected []string) {
+	if !reflect.DeepEqual(expected, value) {
+		t.Errorf("expected %s to be %v, but got %v", name, expected, value)
+	}
+}
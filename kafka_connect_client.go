@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+)
+
+const (
+	connectorRunning = "RUNNING"
+	connectorPaused  = "PAUSED"
+	connectorFailed  = "FAILED"
+)
+
+// KafkaConnectClient manages connectors through the Kafka Connect REST API.
+type KafkaConnectClient struct {
+	restClient
+}
+
+func newKafkaConnectClient(connectURL string, username string, password string) *KafkaConnectClient {
+	return &KafkaConnectClient{newRestClient(connectURL, username, password, "application/json")}
+}
+
+// ConnectorStatus is the state of a connector and of each of its tasks.
+type ConnectorStatus struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Connector struct {
+		State    string `json:"state"`
+		WorkerID string `json:"worker_id"`
+		Trace    string `json:"trace"`
+	} `json:"connector"`
+	Tasks []ConnectorTaskStatus `json:"tasks"`
+}
+
+// ConnectorTaskStatus is the state of a connector task; Trace holds the
+// stack trace of a failed one.
+type ConnectorTaskStatus struct {
+	ID       int    `json:"id"`
+	State    string `json:"state"`
+	WorkerID string `json:"worker_id"`
+	Trace    string `json:"trace"`
+}
+
+func connectorPath(name string) string {
+	return "/connectors/" + url.PathEscape(name)
+}
+
+// putConnectorConfig creates a connector, or replaces the config of an
+// existing one.
+func (client *KafkaConnectClient) putConnectorConfig(name string, config map[string]string) error {
+	return client.do("PUT", connectorPath(name)+"/config", config, nil)
+}
+
+// connectorConfig returns the config of a connector, without the name
+// Connect adds to it.
+func (client *KafkaConnectClient) connectorConfig(name string) (map[string]string, error) {
+	config := make(map[string]string)
+	if err := client.do("GET", connectorPath(name)+"/config", nil, &config); err != nil {
+		return nil, err
+	}
+	delete(config, "name")
+	return config, nil
+}
+
+func (client *KafkaConnectClient) connectorStatus(name string) (*ConnectorStatus, error) {
+	var status ConnectorStatus
+	if err := client.do("GET", connectorPath(name)+"/status", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (client *KafkaConnectClient) pauseConnector(name string) error {
+	return client.do("PUT", connectorPath(name)+"/pause", nil, nil)
+}
+
+func (client *KafkaConnectClient) resumeConnector(name string) error {
+	return client.do("PUT", connectorPath(name)+"/resume", nil, nil)
+}
+
+func (client *KafkaConnectClient) deleteConnector(name string) error {
+	return client.do("DELETE", connectorPath(name), nil, nil)
+}
+
+// isConnectRebalancing tells whether Connect refused a request because its
+// workers are rebalancing, which is worth retrying.
+func isConnectRebalancing(err error) bool {
+	return isRestAPIStatus(err, http.StatusConflict)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeConnect is a stand-in for the parts of the Kafka Connect REST API the
+// provider uses, answering 409 to the first request to show a rebalance.
+type fakeConnect struct {
+	configs     map[string]map[string]string
+	states      map[string]string
+	rebalancing bool
+}
+
+func (c *fakeConnect) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if c.rebalancing {
+		c.rebalancing = false
+		writeConnectError(w, http.StatusConflict, "Cannot complete request momentarily due to stale configuration (typically caused by a concurrent config change)")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	name := parts[1]
+	config, exists := c.configs[name]
+	if !exists && !(len(parts) == 3 && parts[2] == "config" && req.Method == "PUT") {
+		writeConnectError(w, http.StatusNotFound, "Connector "+name+" not found")
+		return
+	}
+
+	switch {
+	case len(parts) == 3 && parts[2] == "config" && req.Method == "PUT":
+		config = map[string]string{}
+		json.NewDecoder(req.Body).Decode(&config)
+		config["name"] = name
+		c.configs[name] = config
+		if !exists {
+			c.states[name] = connectorRunning
+			w.WriteHeader(http.StatusCreated)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"name": name, "config": config})
+
+	case len(parts) == 3 && parts[2] == "config":
+		json.NewEncoder(w).Encode(config)
+
+	case len(parts) == 3 && parts[2] == "status":
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"name":      name,
+			"type":      "sink",
+			"connector": map[string]string{"state": c.states[name], "worker_id": "10.0.0.1:8083"},
+			"tasks": []map[string]interface{}{
+				{"id": 0, "state": c.states[name], "worker_id": "10.0.0.1:8083"},
+				{"id": 1, "state": connectorFailed, "worker_id": "10.0.0.2:8083", "trace": "java.lang.RuntimeException"},
+			},
+		})
+
+	case len(parts) == 3 && parts[2] == "pause":
+		c.states[name] = connectorPaused
+		w.WriteHeader(http.StatusAccepted)
+
+	case len(parts) == 3 && parts[2] == "resume":
+		c.states[name] = connectorRunning
+		w.WriteHeader(http.StatusAccepted)
+
+	case len(parts) == 2 && req.Method == "DELETE":
+		delete(c.configs, name)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func writeConnectError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"error_code": status, "message": message})
+}
+
+func TestKafkaConnectClient(t *testing.T) {
+	fake := &fakeConnect{configs: map[string]map[string]string{}, states: map[string]string{}, rebalancing: true}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	client := newKafkaConnectClient(server.URL, "", "")
+
+	config := map[string]string{"connector.class": "FileStreamSink", "topics": "orders", "file": "/tmp/orders"}
+	if err := client.putConnectorConfig("orders-sink", config); !isConnectRebalancing(err) {
+		t.Fatalf("expected a rebalance error, got %v", err)
+	}
+	if err := client.putConnectorConfig("orders-sink", config); err != nil {
+		t.Fatal(err)
+	}
+
+	running, err := client.connectorConfig("orders-sink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStringMap(t, "running config", running, config)
+
+	if err := client.pauseConnector("orders-sink"); err != nil {
+		t.Fatal(err)
+	}
+	status, err := client.connectorStatus("orders-sink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Type != "sink" || status.Connector.State != connectorPaused || len(status.Tasks) != 2 || status.Tasks[1].Trace == "" {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	if err := client.deleteConnector("orders-sink"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.connectorConfig("orders-sink"); !isRestAPINotFound(err) {
+		t.Errorf("expected connector to be deleted, got %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	offsetResetEarliest  = "earliest"
+	offsetResetLatest    = "latest"
+	offsetResetTimestamp = "timestamp"
+	offsetResetOffset    = "offset"
+	offsetResetShiftBy   = "shift_by"
+)
+
+// GroupOffset is the committed offset of a consumer group on a partition.
+type GroupOffset struct {
+	Topic     string
+	Partition int
+	Offset    int64
+}
+
+// OffsetReset says where kafka-consumer-groups moves the committed offsets
+// of a group: to the earliest or latest offset, to the first offset at a
+// time, to an offset, or by a number of offsets.
+type OffsetReset struct {
+	Strategy  string
+	Timestamp time.Time
+	Offset    int64
+}
+
+func (r OffsetReset) args() []string {
+	switch r.Strategy {
+	case offsetResetEarliest:
+		return []string{"--to-earliest"}
+	case offsetResetLatest:
+		return []string{"--to-latest"}
+	case offsetResetTimestamp:
+		return []string{"--to-datetime", r.Timestamp.UTC().Format("2006-01-02T15:04:05.000-07:00")}
+	case offsetResetOffset:
+		return []string{"--to-offset", strconv.FormatInt(r.Offset, 10)}
+	default:
+		return []string{"--shift-by", strconv.FormatInt(r.Offset, 10)}
+	}
+}
+
+// topicPartitionsArg names a topic, or some of its partitions, the way
+// kafka-consumer-groups --topic expects.
+func topicPartitionsArg(topic string, partitions []int) string {
+	if len(partitions) == 0 {
+		return topic
+	}
+	ids := make([]string, len(partitions))
+	for i, p := range partitions {
+		ids[i] = strconv.Itoa(p)
+	}
+	return topic + ":" + strings.Join(ids, ",")
+}
+
+func (client *KafkaManagingClient) consumerGroupsCommand(args ...string) (*exec.Cmd, error) {
+	if client.ConsumerGroupsScript == "" {
+		return nil, fmt.Errorf("Unable to find kafka-consumer-groups to manage consumer groups")
+	}
+	if client.BootstrapServers == "" {
+		return nil, fmt.Errorf("Managing consumer groups needs bootstrap_servers")
+	}
+	return exec.Command(client.ConsumerGroupsScript, append([]string{"--bootstrap-server", client.BootstrapServers}, args...)...), nil
+}
+
+// resetConsumerGroupOffsets moves the committed offsets of a group on the
+// given topics, each possibly restricted to some partitions as built by
+// topicPartitionsArg. Kafka refuses to do it while the group has members.
+func (client *KafkaManagingClient) resetConsumerGroupOffsets(group string, topics []string, reset OffsetReset) error {
+	args := []string{"--group", group, "--reset-offsets"}
+	for _, t := range topics {
+		args = append(args, "--topic", t)
+	}
+	args = append(args, reset.args()...)
+	args = append(args, "--execute")
+
+	cmd, err := client.consumerGroupsCommand(args...)
+	if err != nil {
+		return err
+	}
+	return execCombinedCommand(cmd)
+}
+
+// consumerGroupState returns the state of a group and its number of
+// members.
+func (client *KafkaManagingClient) consumerGroupState(group string) (string, int, error) {
+	cmd, err := client.consumerGroupsCommand("--describe", "--group", group, "--state")
+	if err != nil {
+		return "", 0, err
+	}
+
+	out, err := cmd.CombinedOutput()
+	if err != nil && !consumerGroupMissing(string(out)) {
+		if kafkaError := readError(string(out)); kafkaError != nil {
+			return "", 0, kafkaError
+		}
+		return "", 0, fmt.Errorf("Unable to describe consumer group '%s': %s: %s", group, err, strings.TrimSpace(string(out)))
+	}
+
+	return readGroupState(string(out))
+}
+
+// consumerGroupOffsets returns the offsets committed by a group.
+func (client *KafkaManagingClient) consumerGroupOffsets(group string) ([]GroupOffset, error) {
+	cmd, err := client.consumerGroupsCommand("--describe", "--group", group)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := cmd.CombinedOutput()
+	if err != nil && !consumerGroupMissing(string(out)) {
+		if kafkaError := readError(string(out)); kafkaError != nil {
+			return nil, kafkaError
+		}
+		return nil, fmt.Errorf("Unable to describe consumer group '%s': %s: %s", group, err, strings.TrimSpace(string(out)))
+	}
+
+	return readGroupOffsets(string(out)), nil
+}
+
+// readGroupState parses the output of kafka-consumer-groups --describe
+// --state, whose last two columns are the state and the member count:
+//
+//	GROUP     COORDINATOR (ID)     ASSIGNMENT-STRATEGY  STATE   #MEMBERS
+//	billing   broker-1:9092 (1)                         Empty   0
+//
+// A group that does not exist, such as one whose offsets have expired, is
+// idle: it is reported as Dead with no members.
+func readGroupState(txt string) (string, int, error) {
+	if consumerGroupMissing(txt) {
+		return "Dead", 0, nil
+	}
+
+	lines := strings.Split(strings.TrimSpace(txt), "\n")
+	for i, line := range lines {
+		header := strings.Fields(line)
+		if len(header) < 2 || header[len(header)-1] != "#MEMBERS" {
+			continue
+		}
+		for _, row := range lines[i+1:] {
+			fields := strings.Fields(row)
+			if len(fields) < 2 {
+				continue
+			}
+			members, err := strconv.Atoi(fields[len(fields)-1])
+			if err != nil {
+				return "", 0, fmt.Errorf("Unable to parse member count of consumer group: %s", row)
+			}
+			return fields[len(fields)-2], members, nil
+		}
+	}
+
+	return "", 0, fmt.Errorf("Unable to parse consumer group state: %s", strings.TrimSpace(txt))
+}
+
+// consumerGroupMissing tells whether kafka-consumer-groups reported the
+// group as unknown.
+func consumerGroupMissing(txt string) bool {
+	return regexp.MustCompile(`Consumer group '.*' does not exist`).MatchString(txt)
+}
+
+// readGroupOffsets parses the offsets kafka-consumer-groups --describe
+// prints, locating the columns by their header since older versions leave
+// out the GROUP one. Partitions without a committed offset are skipped, as
+// is a group that does not exist, such as one whose offsets have expired.
+func readGroupOffsets(txt string) []GroupOffset {
+	if consumerGroupMissing(txt) {
+		return nil
+	}
+
+	var offsets []GroupOffset
+	columns := map[string]int{}
+
+	for _, line := range strings.Split(txt, "\n") {
+		fields := strings.Fields(line)
+
+		if len(fields) > 0 && (fields[0] == "TOPIC" || fields[0] == "GROUP") {
+			columns = map[string]int{}
+			for i, f := range fields {
+				columns[f] = i
+			}
+			continue
+		}
+
+		ti, tok := columns["TOPIC"]
+		pi, pok := columns["PARTITION"]
+		oi, ook := columns["CURRENT-OFFSET"]
+		if !tok || !pok || !ook || len(fields) <= oi || len(fields) <= pi || len(fields) <= ti {
+			continue
+		}
+
+		partition, err := strconv.Atoi(fields[pi])
+		if err != nil {
+			continue
+		}
+		offset, err := strconv.ParseInt(fields[oi], 10, 64)
+		if err != nil {
+			continue
+		}
+		offsets = append(offsets, GroupOffset{Topic: fields[ti], Partition: partition, Offset: offset})
+	}
+
+	return offsets
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReadGroupState(t *testing.T) {
+	state, members, err := readGroupState(`
+GROUP                     COORDINATOR (ID)          ASSIGNMENT-STRATEGY  STATE           #MEMBERS
+billing                   broker-1:9092 (1)         range                Stable          2
+`)
+	if err != nil || state != "Stable" || members != 2 {
+		t.Errorf("unexpected result %s %d %v", state, members, err)
+	}
+
+	state, members, err = readGroupState(`
+GROUP                     COORDINATOR (ID)          ASSIGNMENT-STRATEGY  STATE           #MEMBERS
+billing                   broker-1:9092 (1)                              Empty           0
+`)
+	if err != nil || state != "Empty" || members != 0 {
+		t.Errorf("unexpected result %s %d %v", state, members, err)
+	}
+
+	for _, txt := range []string{
+		"Consumer group 'billing' does not exist.",
+		"Error: Consumer group 'billing' does not exist.",
+	} {
+		state, members, err = readGroupState(txt)
+		if err != nil || state != "Dead" || members != 0 {
+			t.Errorf("unexpected result for a missing group %s %d %v", state, members, err)
+		}
+	}
+
+	if _, _, err := readGroupState("GROUP  COORDINATOR (ID)"); err == nil {
+		t.Errorf("expected an error without state table")
+	}
+}
+
+func TestReadGroupOffsets(t *testing.T) {
+	expected := []GroupOffset{
+		{Topic: "orders", Partition: 0, Offset: 42},
+		{Topic: "orders", Partition: 1, Offset: 7},
+	}
+
+	current := `
+Consumer group 'billing' has no active members.
+
+GROUP           TOPIC           PARTITION  CURRENT-OFFSET  LOG-END-OFFSET  LAG             CONSUMER-ID     HOST            CLIENT-ID
+billing         orders          0          42              50              8               -               -               -
+billing         orders          1          7               7               0               -               -               -
+billing         orders          2          -               3               -               -               -               -
+`
+	old := `
+TOPIC                          PARTITION  CURRENT-OFFSET  LOG-END-OFFSET  LAG        CONSUMER-ID                                       HOST                           CLIENT-ID
+orders                         0          42              50              8          consumer-1-9e3c2b14-5d0a-4bd8-8a9c-0a4f1e6d7f20   /10.0.0.1                      consumer-1
+orders                         1          7               7               0          consumer-1-9e3c2b14-5d0a-4bd8-8a9c-0a4f1e6d7f20   /10.0.0.1                      consumer-1
+`
+
+	for _, txt := range []string{current, old} {
+		if offsets := readGroupOffsets(txt); !reflect.DeepEqual(offsets, expected) {
+			t.Errorf("expected %v, got %v", expected, offsets)
+		}
+	}
+
+	if offsets := readGroupOffsets("Error: Consumer group 'billing' does not exist.\n"); len(offsets) != 0 {
+		t.Errorf("expected no offsets for a missing group, got %v", offsets)
+	}
+}
+
+func TestOffsetReset_args(t *testing.T) {
+	ts, _ := time.Parse(time.RFC3339, "2018-05-01T12:30:00+02:00")
+
+	assertStrings(t, "timestamp", OffsetReset{Strategy: offsetResetTimestamp, Timestamp: ts}.args(),
+		[]string{"--to-datetime", "2018-05-01T10:30:00.000+00:00"})
+	assertStrings(t, "shift_by", OffsetReset{Strategy: offsetResetShiftBy, Offset: -10}.args(),
+		[]string{"--shift-by", "-10"})
+	assertStrings(t, "partitions", []string{topicPartitionsArg("orders", []int{0, 2}), topicPartitionsArg("audit", nil)},
+		[]string{"orders:0,2", "audit"})
+}
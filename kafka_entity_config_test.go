@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestReadEntityConfig(t *testing.T) {
+	assertStringMap(t, "quotas", readEntityConfig("Configs for user-principal 'alice' are producer_byte_rate=1024,request_percentage=50.5\n"),
+		map[string]string{"producer_byte_rate": "1024", "request_percentage": "50.5"})
+
+	assertStringMap(t, "topic", readEntityConfig("Configs for topic 'orders' are cleanup.policy=compact,delete,retention.ms=1000\n"),
+		map[string]string{"cleanup.policy": "compact,delete", "retention.ms": "1000"})
+
+	assertStringMap(t, "empty", readEntityConfig("Configs for user-principal 'alice', client-id 'app' are \n"), map[string]string{})
+}
+
+func TestConfigEntity_args(t *testing.T) {
+	assertStrings(t, "user and client", quotaEntity("alice", "app").args(),
+		[]string{"--entity-type", "users", "--entity-name", "alice", "--entity-type", "clients", "--entity-name", "app"})
+
+	assertStrings(t, "default client", quotaEntity("", entityDefault).args(),
+		[]string{"--entity-type", "clients", "--entity-default"})
+
+	assertStrings(t, "topic", topicEntity("orders").args(),
+		[]string{"--entity-type", "topics", "--entity-name", "orders"})
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	electionPreferred = "PREFERRED"
+	electionUnclean   = "UNCLEAN"
+)
+
+// electLeaders runs a leader election for every partition of a topic. It
+// uses kafka-leader-election when the brokers can be reached, and falls back
+// on kafka-preferred-replica-election through ZooKeeper otherwise, which can
+// only run preferred elections. Both read the partitions from the same JSON
+// file.
+func (client *KafkaManagingClient) electLeaders(name string, electionType string) error {
+	info, err := client.describeTopic(name)
+	if err != nil {
+		return err
+	}
+	if !info.exists() {
+		return fmt.Errorf("Kafka topic '%s' not found", name)
+	}
+
+	path, err := writePreferredReplicaElectionFile(name, info.Partitions)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	script, args, err := client.leaderElectionCommand(electionType, path)
+	if err != nil {
+		return err
+	}
+	return execCombinedCommand(exec.Command(script, args...))
+}
+
+// leaderElectionCommand picks the election script and builds its options
+// for the partitions listed in the file at path.
+func (client *KafkaManagingClient) leaderElectionCommand(electionType string, path string) (string, []string, error) {
+	if client.LeaderElectionScript != "" && client.BootstrapServers != "" {
+		return client.LeaderElectionScript, []string{
+			"--bootstrap-server", client.BootstrapServers,
+			"--election-type", electionType,
+			"--path-to-json-file", path,
+		}, nil
+	}
+
+	if electionType != electionPreferred {
+		return "", nil, fmt.Errorf("%s leader election needs bootstrap_servers and the kafka-leader-election script", strings.ToLower(electionType))
+	}
+	if client.PreferredReplicaElectionScript == "" {
+		return "", nil, fmt.Errorf("Unable to find kafka-leader-election or kafka-preferred-replica-election for leader election")
+	}
+
+	return client.PreferredReplicaElectionScript, []string{
+		"--zookeeper", client.Zookeeper,
+		"--path-to-json-file", path,
+	}, nil
+}
+
+type electionPartition struct {
+	Topic     string `json:"topic"`
+	Partition int    `json:"partition"`
+}
+
+type electionFile struct {
+	Partitions []electionPartition `json:"partitions"`
+}
+
+// writePreferredReplicaElectionFile writes the partitions of a topic in the
+// format kafka-preferred-replica-election reads, returning the file's path.
+func writePreferredReplicaElectionFile(name string, partitions []PartitionInfo) (string, error) {
+	content := electionFile{Partitions: []electionPartition{}}
+	for _, p := range partitions {
+		content.Partitions = append(content.Partitions, electionPartition{Topic: name, Partition: p.ID})
+	}
+
+	return writeJSONFile("terraform-kafka-election", content)
+}
+
+// writeJSONFile writes a value as JSON to a new temporary file for a Kafka
+// tool to read, returning the file's path. The caller removes the file.
+func writeJSONFile(prefix string, v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return writeTempFile(prefix, data)
+}
+
+// writeTempFile writes data to a new temporary file, readable by its owner
+// only, returning the file's path. The caller removes the file.
+func writeTempFile(prefix string, data []byte) (string, error) {
+	f, err := ioutil.TempFile("", prefix)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestLeaderElectionCommand(t *testing.T) {
+	client := &KafkaManagingClient{
+		Zookeeper:                      "zk:2181",
+		BootstrapServers:               "broker:9092",
+		LeaderElectionScript:           "/bin/kafka-leader-election",
+		PreferredReplicaElectionScript: "/bin/kafka-preferred-replica-election",
+	}
+
+	script, args, err := client.leaderElectionCommand(electionUnclean, "/tmp/election.json")
+	if err != nil || script != "/bin/kafka-leader-election" {
+		t.Fatalf("unexpected result %s %v", script, err)
+	}
+	assertStrings(t, "leader election args", args, []string{
+		"--bootstrap-server", "broker:9092", "--election-type", electionUnclean, "--path-to-json-file", "/tmp/election.json",
+	})
+
+	client.BootstrapServers = ""
+	script, args, err = client.leaderElectionCommand(electionPreferred, "/tmp/election.json")
+	if err != nil || script != "/bin/kafka-preferred-replica-election" {
+		t.Fatalf("unexpected result %s %v", script, err)
+	}
+	assertStrings(t, "preferred replica election args", args, []string{
+		"--zookeeper", "zk:2181", "--path-to-json-file", "/tmp/election.json",
+	})
+
+	if _, _, err := client.leaderElectionCommand(electionUnclean, "/tmp/election.json"); err == nil {
+		t.Errorf("expected unclean election to need bootstrap_servers")
+	}
+}
+
+func TestWritePreferredReplicaElectionFile(t *testing.T) {
+	path, err := writePreferredReplicaElectionFile("orders", []PartitionInfo{{ID: 0}, {ID: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(path)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var content electionFile
+	if err := json.Unmarshal(data, &content); err != nil {
+		t.Fatal(err)
+	}
+	expected := electionFile{Partitions: []electionPartition{{Topic: "orders", Partition: 0}, {Topic: "orders", Partition: 1}}}
+	if !reflect.DeepEqual(content, expected) {
+		t.Errorf("expected %v, got %v", expected, content)
+	}
+}
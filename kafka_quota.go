@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	quotaProducerByteRate  = "producer_byte_rate"
+	quotaConsumerByteRate  = "consumer_byte_rate"
+	quotaRequestPercentage = "request_percentage"
+)
+
+func isQuotaKey(key string) bool {
+	return key == quotaProducerByteRate || key == quotaConsumerByteRate || key == quotaRequestPercentage
+}
+
+// quotaEntity names the entity a quota applies to: a user, a client-id, or
+// a client-id of a user. Either may be entityDefault.
+func quotaEntity(user string, clientID string) configEntity {
+	var entity configEntity
+	if user != "" {
+		entity = append(entity, entityPart{Type: "users", Name: user})
+	}
+	if clientID != "" {
+		entity = append(entity, entityPart{Type: "clients", Name: clientID})
+	}
+	return entity
+}
+
+// quotaID joins the user and client-id of a quota, either possibly empty.
+// Principals may hold slashes, as Kerberos ones do, so a bar separates them.
+func quotaID(user string, clientID string) string {
+	return user + "|" + clientID
+}
+
+func parseQuotaID(id string) (string, string, error) {
+	parts := strings.SplitN(id, "|", 2)
+	if len(parts) != 2 || (parts[0] == "" && parts[1] == "") {
+		return "", "", fmt.Errorf("Invalid quota ID '%s', expected user|client_id with either possibly empty", id)
+	}
+	return parts[0], parts[1], nil
+}
+
+// checkQuotaRates refuses negative rates other than -1, and a quota
+// without any rate, which would set nothing.
+func checkQuotaRates(producerByteRate int, consumerByteRate int, requestPercentage float64) error {
+	rates := []int{producerByteRate, consumerByteRate}
+	for i, k := range []string{quotaProducerByteRate, quotaConsumerByteRate} {
+		if rates[i] < -1 {
+			return fmt.Errorf("%s must be -1 or at least 0, got %d", k, rates[i])
+		}
+	}
+	if requestPercentage < 0 && requestPercentage != -1 {
+		return fmt.Errorf("%s must be -1 or at least 0, got %v", quotaRequestPercentage, requestPercentage)
+	}
+	if producerByteRate == -1 && consumerByteRate == -1 && requestPercentage == -1 {
+		return fmt.Errorf("A kafka_quota needs at least one of %s, %s and %s", quotaProducerByteRate, quotaConsumerByteRate, quotaRequestPercentage)
+	}
+	return nil
+}
+
+// quotaConfig keeps the quota keys of an entity's overrides.
+func quotaConfig(conf map[string]string) map[string]string {
+	quotas := make(map[string]string)
+	for k, v := range conf {
+		if isQuotaKey(k) {
+			quotas[k] = v
+		}
+	}
+	return quotas
+}
@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestQuotaID(t *testing.T) {
+	user, clientID, err := parseQuotaID(quotaID("alice/host@REALM", ""))
+	if err != nil || user != "alice/host@REALM" || clientID != "" {
+		t.Errorf("unexpected result %s %s %v", user, clientID, err)
+	}
+
+	if _, _, err := parseQuotaID("|"); err == nil {
+		t.Errorf("expected an error for an ID without user and client_id")
+	}
+}
+
+func TestCheckQuotaRates(t *testing.T) {
+	if err := checkQuotaRates(1024, -1, -1); err != nil {
+		t.Errorf("unexpected error %s", err)
+	}
+	if err := checkQuotaRates(-1, -1, 50.5); err != nil {
+		t.Errorf("unexpected error %s", err)
+	}
+	if err := checkQuotaRates(-1, -1, -1); err == nil {
+		t.Errorf("expected an error for a quota without any rate")
+	}
+	if err := checkQuotaRates(-2, -1, -1); err == nil {
+		t.Errorf("expected an error for a negative rate")
+	}
+}
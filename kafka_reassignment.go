@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	reassignmentComplete   = "complete"
+	reassignmentInProgress = "in_progress"
+	reassignmentFailed     = "failed"
+)
+
+// PartitionReassignment is the target replica list of a partition.
+type PartitionReassignment struct {
+	Topic     string `json:"topic"`
+	Partition int    `json:"partition"`
+	Replicas  []int  `json:"replicas"`
+}
+
+func (r PartitionReassignment) String() string {
+	return r.Topic + "-" + strconv.Itoa(r.Partition)
+}
+
+type reassignmentFile struct {
+	Version    int                     `json:"version"`
+	Partitions []PartitionReassignment `json:"partitions"`
+}
+
+// executeReassignment submits a reassignment, throttling the replication
+// it causes to throttle bytes per second when throttle is positive.
+func (client *KafkaManagingClient) executeReassignment(partitions []PartitionReassignment, throttle int) error {
+	args := []string{"--execute"}
+	if throttle > 0 {
+		args = append(args, "--throttle", strconv.Itoa(throttle))
+	}
+
+	out, err := client.reassignPartitions(partitions, args...)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(out, "Successfully started") {
+		return fmt.Errorf("Unable to start partition reassignment: %s", strings.TrimSpace(out))
+	}
+	return nil
+}
+
+// verifyReassignment returns the status of each partition of a
+// reassignment, keyed as topic-partition. Once every partition is done,
+// verifying also removes the throttle the reassignment was executed with.
+func (client *KafkaManagingClient) verifyReassignment(partitions []PartitionReassignment) (map[string]string, error) {
+	out, err := client.reassignPartitions(partitions, "--verify")
+	if err != nil {
+		return nil, err
+	}
+	return readReassignmentStatus(out), nil
+}
+
+func (client *KafkaManagingClient) reassignPartitions(partitions []PartitionReassignment, args ...string) (string, error) {
+	if client.ReassignPartitionsScript == "" {
+		return "", fmt.Errorf("Unable to find kafka-reassign-partitions to reassign partitions")
+	}
+
+	path, err := writeJSONFile("terraform-kafka-reassignment", reassignmentFile{Version: 1, Partitions: partitions})
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(path)
+
+	params := append([]string{"--zookeeper", client.Zookeeper, "--reassignment-json-file", path}, args...)
+	cmd := exec.Command(client.ReassignPartitionsScript, params...)
+	log.Printf("[DEBUG] Will execute %v", cmd.Args)
+
+	out, err := cmd.CombinedOutput()
+	if kafkaError := readError(string(out)); kafkaError != nil {
+		return "", kafkaError
+	}
+	if err != nil {
+		return "", fmt.Errorf("Unable to execute command '%v': %s: %s", cmd.Args, err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// readReassignmentStatus parses the partition lines of
+// kafka-reassign-partitions --verify, in either of its spellings:
+//
+//	Reassignment of partition orders-0 completed successfully
+//	Reassignment of partition orders-0 is complete.
+//	Reassignment of partition orders-1 is still in progress
+//	Reassignment of partition orders-2 failed
+func readReassignmentStatus(txt string) map[string]string {
+	statusR := regexp.MustCompile(`(?m:^Reassignment of partition (\S+) (completed successfully|is complete|is still in progress|failed)\.?\s*$)`)
+	status := make(map[string]string)
+
+	for _, m := range statusR.FindAllStringSubmatch(txt, -1) {
+		switch m[2] {
+		case "is still in progress":
+			status[m[1]] = reassignmentInProgress
+		case "failed":
+			status[m[1]] = reassignmentFailed
+		default:
+			status[m[1]] = reassignmentComplete
+		}
+	}
+	return status
+}
+
+// reassignmentProgress sums up the status of every partition: failed if
+// any failed, in progress if any is not known to be complete, complete
+// otherwise.
+func reassignmentProgress(partitions []PartitionReassignment, status map[string]string) string {
+	progress := reassignmentComplete
+	for _, p := range partitions {
+		switch status[p.String()] {
+		case reassignmentFailed:
+			return reassignmentFailed
+		case reassignmentComplete:
+		default:
+			progress = reassignmentInProgress
+		}
+	}
+	return progress
+}
+
+// checkReassignment refuses partitions listed twice and replica lists that
+// are empty or repeat a broker.
+func checkReassignment(partitions []PartitionReassignment) error {
+	seen := make(map[string]bool)
+	for _, p := range partitions {
+		if seen[p.String()] {
+			return fmt.Errorf("Partition %s is listed more than once", p)
+		}
+		seen[p.String()] = true
+
+		if len(p.Replicas) == 0 {
+			return fmt.Errorf("Partition %s needs at least one replica", p)
+		}
+		brokers := make(map[int]bool)
+		for _, b := range p.Replicas {
+			if brokers[b] {
+				return fmt.Errorf("Partition %s lists broker %d more than once", p, b)
+			}
+			brokers[b] = true
+		}
+	}
+	return nil
+}
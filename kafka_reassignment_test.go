@@ -0,0 +1,71 @@
+package main
+
+import "testing"
+
+func TestReadReassignmentStatus(t *testing.T) {
+	old := `Status of partition reassignment: 
+Reassignment of partition orders-0 completed successfully
+Reassignment of partition orders-1 is still in progress
+Reassignment of partition orders-2 failed
+`
+	current := `Status of partition reassignment:
+Reassignment of partition orders-0 is complete.
+Reassignment of partition orders-1 is still in progress.
+Reassignment of partition orders-2 failed.
+`
+	expected := map[string]string{
+		"orders-0": reassignmentComplete,
+		"orders-1": reassignmentInProgress,
+		"orders-2": reassignmentFailed,
+	}
+
+	for _, txt := range []string{old, current} {
+		assertStringMap(t, "status", readReassignmentStatus(txt), expected)
+	}
+}
+
+func TestReassignmentProgress(t *testing.T) {
+	partitions := []PartitionReassignment{
+		{Topic: "orders", Partition: 0, Replicas: []int{1, 2}},
+		{Topic: "orders", Partition: 1, Replicas: []int{2, 3}},
+	}
+
+	if p := reassignmentProgress(partitions, map[string]string{"orders-0": reassignmentComplete}); p != reassignmentInProgress {
+		t.Errorf("expected partition missing from status to be in progress, got %s", p)
+	}
+	if p := reassignmentProgress(partitions, map[string]string{"orders-0": reassignmentComplete, "orders-1": reassignmentComplete}); p != reassignmentComplete {
+		t.Errorf("expected complete, got %s", p)
+	}
+	if p := reassignmentProgress(partitions, map[string]string{"orders-0": reassignmentFailed}); p != reassignmentFailed {
+		t.Errorf("expected failed, got %s", p)
+	}
+}
+
+func TestCheckReassignment(t *testing.T) {
+	if err := checkReassignment([]PartitionReassignment{{Topic: "orders", Partition: 0, Replicas: []int{1, 2}}}); err != nil {
+		t.Errorf("unexpected error %s", err)
+	}
+
+	for _, partitions := range [][]PartitionReassignment{
+		{{Topic: "orders", Partition: 0, Replicas: []int{1}}, {Topic: "orders", Partition: 0, Replicas: []int{2}}},
+		{{Topic: "orders", Partition: 0, Replicas: []int{}}},
+		{{Topic: "orders", Partition: 0, Replicas: []int{1, 1}}},
+	} {
+		if err := checkReassignment(partitions); err == nil {
+			t.Errorf("expected an error for %v", partitions)
+		}
+	}
+}
+
+func TestValidateReassignmentThrottle(t *testing.T) {
+	for _, throttle := range []int{-1, 1, 10485760} {
+		if _, errs := validateReassignmentThrottle(throttle, "throttle"); len(errs) > 0 {
+			t.Errorf("unexpected errors for %d: %v", throttle, errs)
+		}
+	}
+	for _, throttle := range []int{0, -2} {
+		if _, errs := validateReassignmentThrottle(throttle, "throttle"); len(errs) == 0 {
+			t.Errorf("expected an error for %d", throttle)
+		}
+	}
+}
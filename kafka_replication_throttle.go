@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Replication throttles are set in two halves: a rate on the brokers, and
+// on each topic the replicas the rate applies to.
+const (
+	leaderThrottledRate       = "leader.replication.throttled.rate"
+	followerThrottledRate     = "follower.replication.throttled.rate"
+	leaderThrottledReplicas   = "leader.replication.throttled.replicas"
+	followerThrottledReplicas = "follower.replication.throttled.replicas"
+)
+
+// isThrottleConfigKey reports whether a config key belongs to replication
+// throttling, which kafka_replication_throttle and partition reassignments
+// manage rather than the entity's own resource.
+func isThrottleConfigKey(key string) bool {
+	switch key {
+	case leaderThrottledRate, followerThrottledRate, leaderThrottledReplicas, followerThrottledReplicas:
+		return true
+	}
+	return false
+}
+
+// throttleRateConfig builds the rate configs of a broker; negative rates
+// are left out.
+func throttleRateConfig(leaderRate int, followerRate int) map[string]string {
+	conf := make(map[string]string)
+	if leaderRate >= 0 {
+		conf[leaderThrottledRate] = strconv.Itoa(leaderRate)
+	}
+	if followerRate >= 0 {
+		conf[followerThrottledRate] = strconv.Itoa(followerRate)
+	}
+	return conf
+}
+
+// throttleReplicasConfig builds the replica configs of a topic from lists
+// of partition:broker pairs, or of a single * for every replica; empty
+// lists are left out.
+func throttleReplicasConfig(leaderReplicas []string, followerReplicas []string) map[string]string {
+	conf := make(map[string]string)
+	if len(leaderReplicas) > 0 {
+		conf[leaderThrottledReplicas] = strings.Join(leaderReplicas, ",")
+	}
+	if len(followerReplicas) > 0 {
+		conf[followerThrottledReplicas] = strings.Join(followerReplicas, ",")
+	}
+	return conf
+}
+
+// throttleConfig keeps the replication throttle keys of an entity's config.
+func throttleConfig(conf map[string]string) map[string]string {
+	throttles := make(map[string]string)
+	for k, v := range conf {
+		if isThrottleConfigKey(k) {
+			throttles[k] = v
+		}
+	}
+	return throttles
+}
+
+var throttledReplicaR = regexp.MustCompile(`^\d+:\d+$`)
+
+func validateThrottledReplica(v interface{}, k string) ([]string, []error) {
+	if s := v.(string); s != "*" && !throttledReplicaR.MatchString(s) {
+		return nil, []error{fmt.Errorf("%s must be partition:broker or *, got '%s'", k, s)}
+	}
+	return nil, nil
+}
+
+// checkThrottledReplicas refuses * mixed with partition:broker pairs.
+func checkThrottledReplicas(replicas []string) error {
+	for _, r := range replicas {
+		if r == "*" && len(replicas) > 1 {
+			return fmt.Errorf("* throttles every replica and cannot be listed with others")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestThrottleConfig(t *testing.T) {
+	assertStringMap(t, "rates", throttleRateConfig(1024, -1),
+		map[string]string{leaderThrottledRate: "1024"})
+
+	assertStringMap(t, "replicas", throttleReplicasConfig([]string{"0:1", "1:2"}, []string{"*"}),
+		map[string]string{leaderThrottledReplicas: "0:1,1:2", followerThrottledReplicas: "*"})
+
+	assertStringMap(t, "unmanaged", unmanagedConfig(
+		map[string]string{leaderThrottledReplicas: "*", "min.insync.replicas": "2"}, map[string]string{}),
+		map[string]string{"min.insync.replicas": "2"})
+}
+
+func TestThrottledReplicas(t *testing.T) {
+	for _, r := range []string{"*", "0:101"} {
+		if _, errs := validateThrottledReplica(r, "leader_replicas"); len(errs) != 0 {
+			t.Errorf("unexpected errors for %s: %v", r, errs)
+		}
+	}
+	if _, errs := validateThrottledReplica("0-101", "leader_replicas"); len(errs) == 0 {
+		t.Errorf("expected an error for 0-101")
+	}
+
+	if err := checkThrottledReplicas([]string{"*", "0:101"}); err == nil {
+		t.Errorf("expected an error for * listed with others")
+	}
+}
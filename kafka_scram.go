@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+var scramMechanisms = []string{"SCRAM-SHA-256", "SCRAM-SHA-512"}
+
+// scramMinIterations and scramMaxIterations bound the iterations Kafka
+// accepts for its SCRAM mechanisms.
+const (
+	scramMinIterations = 4096
+	scramMaxIterations = 16384
+)
+
+// alterScramCredential sets the credential of a user for a mechanism,
+// replacing any previous one. The password is kept out of logs, errors and
+// the command line: kafka-configs reads it from a file only the current
+// user can read.
+func (client *KafkaManagingClient) alterScramCredential(user string, mechanism string, iterations int, password string) error {
+	entity := configEntity{{Type: "users", Name: user}}
+
+	path, err := writeTempFile("terraform-kafka-scram", []byte(scramCredentialProperty(mechanism, iterations, password)))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	params := append([]string{"--zookeeper", client.Zookeeper}, entity.args()...)
+	params = append(params, "--alter", "--add-config-file", path)
+
+	log.Printf("Will set %s credential with %d iterations for %s", mechanism, iterations, entity)
+	cmd := exec.Command(client.ConfigScript, params...)
+
+	out, err := cmd.Output()
+	if err != nil {
+		kafkaError := readError(string(out))
+		if kafkaError != nil {
+			return kafkaError
+		}
+		return fmt.Errorf("Unable to set %s credential for %s: %s", mechanism, entity, err)
+	}
+
+	if !strings.Contains(string(out), "Completed") {
+		return fmt.Errorf("Unable to set %s credential for %s: %s", mechanism, entity, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// scramCredentialProperty writes a credential as the properties file line
+// kafka-configs --add-config-file reads. Unlike --add-config, the file's
+// values take no brackets. Properties files are read as ISO-8859-1, so
+// backslashes and anything outside printable ASCII are escaped.
+func scramCredentialProperty(mechanism string, iterations int, password string) string {
+	var escaped []string
+	for _, r := range password {
+		switch {
+		case r == '\\':
+			escaped = append(escaped, `\\`)
+		case r < 0x20 || r > 0x7e:
+			for _, u := range utf16.Encode([]rune{r}) {
+				escaped = append(escaped, fmt.Sprintf(`\u%04x`, u))
+			}
+		default:
+			escaped = append(escaped, string(r))
+		}
+	}
+	return fmt.Sprintf("%s=iterations=%d,password=%s\n", mechanism, iterations, strings.Join(escaped, ""))
+}
+
+func (client *KafkaManagingClient) deleteScramCredential(user string, mechanism string) error {
+	mods := makeConfMods()
+	mods.ConfDeletions[mechanism] = ""
+	return client.alterEntityConfig(configEntity{{Type: "users", Name: user}}, mods)
+}
+
+// scramCredentials returns the iterations of each SCRAM credential of a
+// user, keyed by mechanism.
+func (client *KafkaManagingClient) scramCredentials(user string) (map[string]int, error) {
+	out, err := client.describeEntity(configEntity{{Type: "users", Name: user}})
+	if err != nil {
+		return nil, err
+	}
+	return readScramCredentials(out), nil
+}
+
+// readScramCredentials parses the SCRAM credentials kafka-configs prints,
+// either through ZooKeeper:
+//
+//	Configs for user-principal 'alice' are SCRAM-SHA-512=salt=c2FsdA==,stored_key=a2V5,server_key=a2V5,iterations=8192
+//
+// or through the brokers:
+//
+//	SCRAM credential configs for user-principal 'alice' are SCRAM-SHA-512=iterations=8192
+func readScramCredentials(txt string) map[string]int {
+	credentialR := regexp.MustCompile(`(SCRAM-SHA-(?:256|512))=(?:salt=[^,]*,stored_key=[^,]*,server_key=[^,]*,)?iterations=(\d+)`)
+	credentials := make(map[string]int)
+
+	for _, m := range credentialR.FindAllStringSubmatch(txt, -1) {
+		iterations, _ := strconv.Atoi(m[2])
+		credentials[m[1]] = iterations
+	}
+	return credentials
+}
+
+// validateScramPassword rejects the characters kafka-configs would take for
+// the end of the credential.
+func validateScramPassword(v interface{}, k string) ([]string, []error) {
+	if strings.ContainsAny(v.(string), ",[]") {
+		return nil, []error{fmt.Errorf("%s must not contain commas or brackets", k)}
+	}
+	if v.(string) == "" {
+		return nil, []error{fmt.Errorf("%s must not be empty", k)}
+	}
+	return nil, nil
+}
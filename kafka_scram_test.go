@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestReadScramCredentials(t *testing.T) {
+	zookeeperOut := "Configs for user-principal 'alice' are SCRAM-SHA-512=salt=c2FsdA==,stored_key=a2V5,server_key=a2V5,iterations=8192,SCRAM-SHA-256=salt=c2FsdA==,stored_key=a2V5,server_key=a2V5,iterations=4096,producer_byte_rate=1024\n"
+	brokerOut := "SCRAM credential configs for user-principal 'alice' are SCRAM-SHA-512=iterations=8192, SCRAM-SHA-256=iterations=4096\n"
+
+	for _, out := range []string{zookeeperOut, brokerOut} {
+		credentials := readScramCredentials(out)
+		if len(credentials) != 2 || credentials["SCRAM-SHA-512"] != 8192 || credentials["SCRAM-SHA-256"] != 4096 {
+			t.Errorf("unexpected credentials %v", credentials)
+		}
+	}
+
+	if credentials := readScramCredentials("Configs for user-principal 'alice' are \n"); len(credentials) != 0 {
+		t.Errorf("expected no credentials, got %v", credentials)
+	}
+}
+
+func TestValidateScramPassword(t *testing.T) {
+	if _, errs := validateScramPassword("s3cret=!", "password"); len(errs) != 0 {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	for _, p := range []string{"", "a,b", "a]b"} {
+		if _, errs := validateScramPassword(p, "password"); len(errs) == 0 {
+			t.Errorf("expected an error for '%s'", p)
+		}
+	}
+}
+
+func TestParseScramCredentialID(t *testing.T) {
+	user, mechanism, err := parseScramCredentialID("svc|orders|SCRAM-SHA-256")
+	if err != nil || user != "svc|orders" || mechanism != "SCRAM-SHA-256" {
+		t.Errorf("unexpected result %s %s %v", user, mechanism, err)
+	}
+	if _, _, err := parseScramCredentialID("alice"); err == nil {
+		t.Errorf("expected an error for an ID without mechanism")
+	}
+}
+
+func TestScramCredentialProperty(t *testing.T) {
+	assertString(t, "credential", scramCredentialProperty("SCRAM-SHA-512", 8192, "s3cret=!"),
+		"SCRAM-SHA-512=iterations=8192,password=s3cret=!\n")
+	assertString(t, "escaped credential", scramCredentialProperty("SCRAM-SHA-256", 4096, `a\bé😀`),
+		`SCRAM-SHA-256=iterations=4096,password=a\\b\u00e9\ud83d\ude00`+"\n")
+}
+
+func TestWriteTempFile(t *testing.T) {
+	path, err := writeTempFile("terraform-kafka-scram", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("expected the file to be readable by its owner only, got %v", mode)
+	}
+}
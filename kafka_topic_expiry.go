@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// topicExpiryRoot is the znode under which the expiry time of each topic
+// created with expires_at or ttl is recorded, one child per topic.
+const topicExpiryRoot = "/terraform-provider-kafka/topic-expiry"
+
+const (
+	zkNoNode     = "Node does not exist"
+	zkNodeExists = "Node already exists"
+)
+
+func (client *KafkaManagingClient) zookeeperShell(args ...string) (string, error) {
+	if client.ZookeeperShellScript == "" {
+		return "", fmt.Errorf("Unable to find the zookeeper-shell script needed for topic expiry")
+	}
+
+	cmd := exec.Command(client.ZookeeperShellScript, append([]string{client.Zookeeper}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("Unable to execute command '%v': %s: %s", cmd.Args, err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+func (client *KafkaManagingClient) setTopicExpiry(name string, expiresAt time.Time) error {
+	path := topicExpiryRoot + "/" + name
+	data := "expires_at=" + expiresAt.UTC().Format(time.RFC3339)
+
+	out, err := client.zookeeperShell("set", path, data)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(out, zkNoNode) {
+		return nil
+	}
+
+	// First expiry for this topic: create the path down to it, which other
+	// topics may have created already.
+	parent := ""
+	for _, part := range strings.Split(strings.TrimPrefix(topicExpiryRoot, "/"), "/") {
+		parent += "/" + part
+		out, err = client.zookeeperShell("create", parent, "-")
+		if err != nil && !strings.Contains(err.Error(), zkNodeExists) {
+			return err
+		}
+		if err == nil && !strings.Contains(out, "Created "+parent) && !strings.Contains(out, zkNodeExists) {
+			return fmt.Errorf("Unable to create '%s' to record topic expiry: %s", parent, strings.TrimSpace(out))
+		}
+	}
+
+	if out, err = client.zookeeperShell("create", path, data); err != nil {
+		return err
+	}
+	if !strings.Contains(out, "Created "+path) {
+		return fmt.Errorf("Unable to record expiry of topic '%s': %s", name, strings.TrimSpace(out))
+	}
+	return nil
+}
+
+// topicExpiry returns the recorded expiry time of a topic, with false if it
+// has none.
+func (client *KafkaManagingClient) topicExpiry(name string) (time.Time, bool, error) {
+	out, err := client.zookeeperShell("get", topicExpiryRoot+"/"+name)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if strings.Contains(out, zkNoNode) {
+		return time.Time{}, false, nil
+	}
+	return readTopicExpiry(out)
+}
+
+func (client *KafkaManagingClient) deleteTopicExpiry(name string) error {
+	out, err := client.zookeeperShell("delete", topicExpiryRoot+"/"+name)
+	if err != nil {
+		return err
+	}
+	if strings.Contains(out, zkNoNode) {
+		log.Printf("[DEBUG] Kafka topic '%s' had no expiry recorded", name)
+	}
+	return nil
+}
+
+// listTopicExpiries returns the expiry time of every topic having one.
+func (client *KafkaManagingClient) listTopicExpiries() (map[string]time.Time, error) {
+	out, err := client.zookeeperShell("ls", topicExpiryRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	expiries := make(map[string]time.Time)
+	if strings.Contains(out, zkNoNode) {
+		return expiries, nil
+	}
+
+	for _, name := range readZookeeperChildren(out) {
+		expiresAt, ok, err := client.topicExpiry(name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			expiries[name] = expiresAt
+		}
+	}
+	return expiries, nil
+}
+
+// readTopicExpiry finds the expiry record in the output of zookeeper-shell
+// get, which depending on the version is surrounded by connection logs and
+// node stats.
+func readTopicExpiry(txt string) (time.Time, bool, error) {
+	expiryR, _ := regexp.Compile("(?m:^expires_at=(\\S+)\\s*$)")
+	m := expiryR.FindStringSubmatch(txt)
+	if m == nil {
+		return time.Time{}, false, nil
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, m[1])
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("Unable to read topic expiry: %s", err)
+	}
+	return expiresAt, true, nil
+}
+
+// readZookeeperChildren parses the "[a, b, c]" line zookeeper-shell ls
+// prints.
+func readZookeeperChildren(txt string) []string {
+	childrenR, _ := regexp.Compile("(?m:^\\[(.*)\\]\\s*$)")
+	children := []string{}
+
+	matches := childrenR.FindAllStringSubmatch(txt, -1)
+	if len(matches) == 0 {
+		return children
+	}
+
+	for _, c := range strings.Split(matches[len(matches)-1][1], ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			children = append(children, c)
+		}
+	}
+	return children
+}
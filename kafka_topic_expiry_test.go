@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+const (
+	zkGetResponse = `Connecting to localhost:2181
+
+WATCHER::
+
+WatchedEvent state:SyncConnected type:None path:null
+expires_at=2026-10-22T09:30:00Z
+cZxid = 0x1d
+ctime = Mon Oct 19 09:30:00 UTC 2026
+dataVersion = 0`
+
+	zkLsResponse = `Connecting to localhost:2181
+
+WATCHER::
+
+WatchedEvent state:SyncConnected type:None path:null
+[preview-42.orders, preview-43.orders]`
+)
+
+func TestReadTopicExpiry(t *testing.T) {
+	expiresAt, ok, err := readTopicExpiry(zkGetResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("expected an expiry to be found")
+	}
+	if !expiresAt.Equal(time.Date(2026, 10, 22, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("unexpected expiry %s", expiresAt)
+	}
+
+	if _, ok, _ = readTopicExpiry("Connecting to localhost:2181\n"); ok {
+		t.Errorf("expected no expiry to be found")
+	}
+}
+
+func TestReadZookeeperChildren(t *testing.T) {
+	assertStrings(t, "children", readZookeeperChildren(zkLsResponse), []string{"preview-42.orders", "preview-43.orders"})
+	assertStrings(t, "no children", readZookeeperChildren("WATCHER::\n[]\n"), []string{})
+}
@@ -1,6 +1,10 @@
 package main
 
-import "strconv"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 type KafkaTopicInfo struct {
 	PartitionsCount   int
@@ -10,113 +14,267 @@ type KafkaTopicInfo struct {
 	RetentionMs       int64
 	SegmentBytes      int64
 	SegmentMs         int64
-	PartitionsCountChanged   bool
-	ReplicationFactorChanged bool
-	CleanupPolicyChanged     bool
-	RetentionBytesChanged    bool
-	RetentionMsChanged       bool
-	SegmentBytesChanged      bool
-	SegmentMsChanged         bool
+	Partitions        []PartitionInfo
+	// Config holds the topic-level overrides: every override reported by
+	// Kafka when read, or the overrides to apply when built from a resource.
+	Config map[string]string
+	// EffectiveConfig holds every config value in effect for the topic,
+	// including broker defaults. It is only filled in when the provider
+	// can reach the brokers.
+	EffectiveConfig map[string]ConfigEntry
 }
 
-type ConfMods struct {
-	ConfDeletions map[string]string
-	ConfAdditions map[string]string
+// PartitionInfo describes the placement and state of one partition.
+type PartitionInfo struct {
+	ID       int
+	Leader   int
+	Replicas []int
+	Isr      []int
 }
 
-func appendConf(slice []string, name string, value string) []string {
-	return append(slice, "--config", name+"="+value)
+// offline reports whether the partition has no leader.
+func (p PartitionInfo) offline() bool {
+	return p.Leader < 0
 }
 
-func setConf(confMods ConfMods, name string, changed bool, value string, nullValue string) {
-	if changed {
-		if nullValue == value {
-			confMods.ConfDeletions[name] = value
-		} else {
-			confMods.ConfAdditions[name] = value
+// TopicDefaults is the provider's default_topic_config, applied to every
+// kafka_topic beneath its own settings.
+type TopicDefaults struct {
+	ReplicationFactor int
+	Config            map[string]string
+}
+
+// mergeTopicConfig lays the overrides declared on a resource over the
+// default config, leaving out the defaults whose key is in ignored.
+func mergeTopicConfig(defaults TopicDefaults, ignored map[string]bool, declared map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for k, v := range defaults.Config {
+		if !ignored[k] {
+			merged[k] = v
 		}
 	}
+	for k, v := range declared {
+		merged[k] = v
+	}
+	return merged
 }
 
-func setConfInt(confMods ConfMods, name string, changed bool, value int, nullValue int) {
-	setConf(confMods, name, changed, strconv.Itoa(value), strconv.Itoa(nullValue))
+// ConfigEntry is a config value together with the place Kafka took it from.
+type ConfigEntry struct {
+	Value     string
+	Source    string
+	Sensitive bool
 }
 
-func setConfInt64(confMods ConfMods, name string, changed bool, value int64, nullValue int64) {
-	setConf(confMods, name, changed, strconv.FormatInt(value, 10), strconv.FormatInt(nullValue, 10))
+// Config sources reported by kafka-configs --describe --all.
+const (
+	configSourceDefault              = "DEFAULT_CONFIG"
+	configSourceDynamicTopic         = "DYNAMIC_TOPIC_CONFIG"
+	configSourceDynamicBroker        = "DYNAMIC_BROKER_CONFIG"
+	configSourceDynamicDefaultBroker = "DYNAMIC_DEFAULT_BROKER_CONFIG"
+	configSourceStaticBroker         = "STATIC_BROKER_CONFIG"
+)
+
+// isOverride reports whether the entry was set explicitly on the topic
+// rather than inherited from the broker.
+func (e ConfigEntry) isOverride() bool {
+	return e.Source == configSourceDynamicTopic
 }
 
-func makeConfMods() ConfMods {
-	confMods := ConfMods {
-		ConfDeletions: make(map[string]string),
-		ConfAdditions: make(map[string]string)}
+// topicConfigUnset is the value of a numeric kafka_topic config attribute
+// that is not set. It cannot be -1, which Kafka takes for unlimited
+// retention.
+const topicConfigUnset = -2
+
+// topicConfigKeys maps kafka_topic attributes to the topic-level Kafka config
+// keys they manage.
+var topicConfigKeys = map[string]string{
+	"cleanup_policy":  "cleanup.policy",
+	"retention_bytes": "retention.bytes",
+	"retention_ms":    "retention.ms",
+	"segment_bytes":   "segment.bytes",
+	"segment_ms":      "segment.ms",
+}
 
-	return confMods
+// unitAttr describes a kafka_topic attribute giving a numeric attribute's
+// value in human-readable form.
+type unitAttr struct {
+	numeric string
+	parse   func(string) (int64, error)
+	format  func(int64) string
 }
 
-func writeConfMods(slice []string, conf *ConfMods) []string {
-	if (len(conf.ConfAdditions) > 0) {
-		arg := ""
-		delim := ""
+// topicConfigUnits maps the human-readable kafka_topic attributes to the
+// numeric attributes they stand in for.
+var topicConfigUnits = map[string]unitAttr{
+	"retention":      {"retention_ms", parseDuration, formatDuration},
+	"retention_size": {"retention_bytes", parseSize, formatSize},
+	"segment":        {"segment_ms", parseDuration, formatDuration},
+	"segment_size":   {"segment_bytes", parseSize, formatSize},
+}
 
-		for k, v := range conf.ConfAdditions {
-			arg += delim + k + "=" + v
-			delim = ","
+// isManagedConfigKey reports whether a Kafka config key is backed by a
+// kafka_topic attribute.
+func isManagedConfigKey(key string) bool {
+	for _, k := range topicConfigKeys {
+		if k == key {
+			return true
 		}
-
-		slice = append(slice, "--add-config")
-		slice = append(slice, arg)
 	}
+	return false
+}
 
-	for k, _ := range conf.ConfDeletions {
-		slice = append(slice, "--delete-config", k)
+// unmanagedConfig returns the overrides from conf which are neither backed by
+// a kafka_topic attribute nor declared in the resource's config map.
+// Replication throttles are not counted: they come and go with
+// reassignments and kafka_replication_throttle.
+func unmanagedConfig(conf map[string]string, declared map[string]string) map[string]string {
+	unmanaged := make(map[string]string)
+	for k, v := range conf {
+		if _, ok := declared[k]; !ok && !isManagedConfigKey(k) && !isThrottleConfigKey(k) {
+			unmanaged[k] = v
+		}
 	}
+	return unmanaged
+}
 
-	return slice
+// createTopicConfigOpts returns the --config options setting the topic's
+// overrides on creation.
+func (conf *KafkaTopicInfo) createTopicConfigOpts() []string {
+	mods := diffConfig(conf.Config, nil, nil)
+	return writeCreateConfMods([]string{}, &mods)
 }
 
-func (conf *KafkaTopicInfo) alterTopicConfigOpts() []string {
-	var parms = []string{}
+// satisfiedBy tells whether the topic read back as info reflects the
+// partitions and config overrides of conf, returning what is still missing.
+// More partitions than asked for are accepted, as an adopted topic may have
+// them.
+func (conf *KafkaTopicInfo) satisfiedBy(info *KafkaTopicInfo) error {
+	if info.PartitionsCount < conf.PartitionsCount {
+		return fmt.Errorf("%d partition(s) out of %d", info.PartitionsCount, conf.PartitionsCount)
+	}
+	for _, k := range sortedKeys(conf.Config) {
+		if v, ok := info.Config[k]; !ok || v != conf.Config[k] {
+			return fmt.Errorf("%s is not %s yet", k, conf.Config[k])
+		}
+	}
+	return nil
+}
 
-	confMods := ConfMods {
-		ConfDeletions: make(map[string]string),
-		ConfAdditions: make(map[string]string)}
+// configStateValue returns the value to keep in state for a config key. An
+// explicit override always wins; a value inherited from the broker is kept
+// when it equals current, so writing the broker default explicitly does not
+// produce a diff. Otherwise unset is returned.
+func (info *KafkaTopicInfo) configStateValue(key string, current string, unset string) string {
+	if v, ok := info.Config[key]; ok {
+		return v
+	}
+	if e, ok := info.EffectiveConfig[key]; ok && !e.isOverride() && e.Value == current {
+		return current
+	}
+	return unset
+}
 
-	setConf     (confMods, "cleanup.policy" , conf.CleanupPolicyChanged, 	conf.CleanupPolicy  , "")
-	setConfInt64(confMods, "retention.bytes", conf.RetentionBytesChanged,	conf.RetentionBytes , -1)
-	setConfInt64(confMods, "retention.ms"   , conf.RetentionMsChanged,   	conf.RetentionMs    , -1)
-	setConfInt64(confMods, "retention.ms"   , conf.RetentionMsChanged,   	conf.RetentionMs    , -1)
-	setConfInt64(confMods, "segment.bytes"  , conf.SegmentBytesChanged,  	conf.SegmentBytes   , -1)
-	setConfInt64(confMods, "segment.ms"     , conf.SegmentMsChanged,     	conf.SegmentMs      , -1)
+func (info *KafkaTopicInfo) configStateInt(key string, current int) int {
+	v := info.configStateValue(key, strconv.Itoa(current), strconv.Itoa(topicConfigUnset))
+	if i, err := strconv.Atoi(v); err == nil {
+		return i
+	}
+	return topicConfigUnset
+}
 
-	parms = writeConfMods(parms, &confMods)
+// topicCheckFailure is returned by checks on a topic's state when waiting
+// cannot make them pass.
+type topicCheckFailure struct {
+	error
+}
 
-	// parms = append(parms, "--moo")
+// preferredLeadersElected tells whether every partition is led by its
+// preferred replica, the first in its replica list. A preferred replica out
+// of the ISR cannot be elected, which is reported as a topicCheckFailure.
+func (info *KafkaTopicInfo) preferredLeadersElected() error {
+	var skewed, outOfSync []string
+	for _, p := range info.Partitions {
+		if len(p.Replicas) == 0 || p.Leader == p.Replicas[0] {
+			continue
+		}
+		skewed = append(skewed, strconv.Itoa(p.ID))
+		if !containsInt(p.Isr, p.Replicas[0]) {
+			outOfSync = append(outOfSync, strconv.Itoa(p.ID))
+		}
+	}
+	if len(outOfSync) > 0 {
+		return topicCheckFailure{fmt.Errorf("preferred replica of partition(s) %s is not in sync and cannot lead", strings.Join(outOfSync, ", "))}
+	}
+	if len(skewed) > 0 {
+		return fmt.Errorf("partition(s) %s not led by their preferred replica", strings.Join(skewed, ", "))
+	}
+	return nil
+}
 
-	return parms
+func containsInt(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
 }
 
-func (conf *KafkaTopicInfo) createTopicConfigOpts() []string {
-	var parms = []string{}
-	if conf.CleanupPolicy != "" {
-		parms = appendConf(parms, "cleanup.policy", conf.CleanupPolicy)
+// leadersElected tells whether every partition has a leader.
+func (info *KafkaTopicInfo) leadersElected() error {
+	if h := info.health(); h.Offline > 0 {
+		return fmt.Errorf("%d partition(s) without a leader", h.Offline)
 	}
-	if conf.RetentionBytes > -1 {
-		parms = appendConf(parms, "retention.bytes", strconv.FormatInt(conf.RetentionBytes, 10))
+	return nil
+}
+
+// TopicHealth counts the partitions of a topic in a degraded state.
+type TopicHealth struct {
+	UnderReplicated int
+	Offline         int
+	IsrBelowMin     int
+}
+
+func (h TopicHealth) healthy() bool {
+	return h.UnderReplicated == 0 && h.Offline == 0 && h.IsrBelowMin == 0
+}
+
+func (h TopicHealth) String() string {
+	return fmt.Sprintf("%d under-replicated, %d offline and %d below min.insync.replicas partition(s)",
+		h.UnderReplicated, h.Offline, h.IsrBelowMin)
+}
+
+// minInSyncReplicas returns the topic's min.insync.replicas, falling back on
+// the broker default when known and on Kafka's own default of 1 otherwise.
+func (info *KafkaTopicInfo) minInSyncReplicas() int {
+	v, ok := info.Config["min.insync.replicas"]
+	if !ok {
+		v = info.EffectiveConfig["min.insync.replicas"].Value
 	}
-	if conf.RetentionMs > -1 {
-		parms = appendConf(parms, "retention.ms", strconv.FormatInt(conf.RetentionMs, 10))
+	if i, err := strconv.Atoi(v); err == nil {
+		return i
 	}
+	return 1
+}
 
-	if conf.SegmentBytes > -1 {
-		parms = appendConf(parms, "segment.bytes", strconv.FormatInt(conf.SegmentBytes, 10))
-	}
+func (info *KafkaTopicInfo) health() TopicHealth {
+	minIsr := info.minInSyncReplicas()
+	h := TopicHealth{}
 
-	if conf.SegmentMs > -1 {
-		parms = appendConf(parms, "segment.ms", strconv.FormatInt(conf.SegmentMs, 10))
+	for _, p := range info.Partitions {
+		if p.offline() {
+			h.Offline++
+		}
+		if len(p.Isr) < len(p.Replicas) {
+			h.UnderReplicated++
+		}
+		if len(p.Isr) < minIsr {
+			h.IsrBelowMin++
+		}
 	}
 
-	return parms
+	return h
 }
 
 func (info *KafkaTopicInfo) exists() bool {
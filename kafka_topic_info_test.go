@@ -0,0 +1,128 @@
+package main
+
+import "testing"
+
+func TestMergeTopicConfig(t *testing.T) {
+	defaults := TopicDefaults{
+		ReplicationFactor: 3,
+		Config: map[string]string{
+			"min.insync.replicas": "2",
+			"compression.type":    "lz4",
+			"retention.ms":        "604800000",
+		},
+	}
+	declared := map[string]string{"retention.ms": "1000", "cleanup.policy": "compact"}
+
+	merged := mergeTopicConfig(defaults, map[string]bool{"compression.type": true}, declared)
+	assertStringMap(t, "merged", merged, map[string]string{
+		"min.insync.replicas": "2",
+		"retention.ms":        "1000",
+		"cleanup.policy":      "compact",
+	})
+
+	assertStringMap(t, "no defaults", mergeTopicConfig(TopicDefaults{}, nil, declared), declared)
+}
+
+func TestKafkaTopicInfo_satisfiedBy(t *testing.T) {
+	conf := &KafkaTopicInfo{
+		PartitionsCount: 6,
+		Config:          map[string]string{"retention.ms": "1000"},
+	}
+
+	for _, info := range []*KafkaTopicInfo{
+		{PartitionsCount: 6, Config: map[string]string{"retention.ms": "1000"}},
+		{PartitionsCount: 8, Config: map[string]string{"retention.ms": "1000", "segment.ms": "10"}},
+	} {
+		if err := conf.satisfiedBy(info); err != nil {
+			t.Errorf("expected %v to satisfy %v, got %s", info, conf, err)
+		}
+	}
+
+	for _, info := range []*KafkaTopicInfo{
+		{PartitionsCount: 3, Config: map[string]string{"retention.ms": "1000"}},
+		{PartitionsCount: 6, Config: map[string]string{}},
+		{PartitionsCount: 6, Config: map[string]string{"retention.ms": "2000"}},
+	} {
+		if err := conf.satisfiedBy(info); err == nil {
+			t.Errorf("expected %v not to satisfy %v", info, conf)
+		}
+	}
+}
+
+func TestKafkaTopicInfo_leaderElection(t *testing.T) {
+	info := &KafkaTopicInfo{Partitions: []PartitionInfo{
+		{ID: 0, Leader: 1, Replicas: []int{1, 2}, Isr: []int{1, 2}},
+		{ID: 1, Leader: 1, Replicas: []int{2, 1}, Isr: []int{1, 2}},
+		{ID: 2, Leader: -1, Replicas: []int{3, 1}, Isr: []int{}},
+	}}
+
+	if err := info.preferredLeadersElected(); err == nil || err.Error() != "preferred replica of partition(s) 2 is not in sync and cannot lead" {
+		t.Errorf("unexpected result %v", err)
+	} else if _, ok := err.(topicCheckFailure); !ok {
+		t.Errorf("expected an out-of-sync preferred replica to fail the check for good, got %T", err)
+	}
+
+	info.Partitions[2].Isr = []int{3}
+	if err := info.preferredLeadersElected(); err == nil || err.Error() != "partition(s) 1, 2 not led by their preferred replica" {
+		t.Errorf("unexpected result %v", err)
+	}
+	if err := info.leadersElected(); err == nil {
+		t.Errorf("expected partition 2 to be reported without a leader")
+	}
+
+	info.Partitions[1].Leader = 2
+	info.Partitions[2].Leader = 3
+	if err := info.preferredLeadersElected(); err != nil {
+		t.Errorf("expected preferred leaders, got %s", err)
+	}
+	if err := info.leadersElected(); err != nil {
+		t.Errorf("expected leaders, got %s", err)
+	}
+}
+
+// topicAttrs stands in for the settings of a kafka_topic resource.
+type topicAttrs map[string]interface{}
+
+func (a topicAttrs) Get(key string) interface{} {
+	if v, ok := a[key]; ok {
+		return v
+	}
+	switch key {
+	case "cleanup_policy", "retention", "retention_size", "segment", "segment_size":
+		return ""
+	case "config":
+		return map[string]interface{}{}
+	}
+	return topicConfigUnset
+}
+
+func TestDesiredTopicConfig_unlimitedRetention(t *testing.T) {
+	desired := desiredTopicConfig(topicAttrs{"retention_ms": -1, "retention_bytes": -1})
+	assertStringMap(t, "desired config", desired, map[string]string{"retention.ms": "-1", "retention.bytes": "-1"})
+
+	assertStrings(t, "create options", (&KafkaTopicInfo{Config: desired}).createTopicConfigOpts(),
+		[]string{"--config", "retention.bytes=-1", "--config", "retention.ms=-1"})
+
+	mods := diffConfig(desired, map[string]string{"retention.ms": "-1"}, isManagedConfigKey)
+	assertStringMap(t, "added on update", mods.ConfAdditions, map[string]string{"retention.bytes": "-1"})
+	assertStringMap(t, "deleted on update", mods.ConfDeletions, map[string]string{})
+
+	mods = diffConfig(desiredTopicConfig(topicAttrs{}), map[string]string{"retention.ms": "-1"}, isManagedConfigKey)
+	assertStringMap(t, "deleted once unset", mods.ConfDeletions, map[string]string{"retention.ms": "-1"})
+}
+
+func TestDesiredTopicConfig_segments(t *testing.T) {
+	desired := desiredTopicConfig(topicAttrs{"segment_bytes": 1073741824, "segment_ms": 60000})
+	assertStringMap(t, "desired config", desired, map[string]string{"segment.bytes": "1073741824", "segment.ms": "60000"})
+
+	assertStrings(t, "create options", (&KafkaTopicInfo{Config: desired}).createTopicConfigOpts(),
+		[]string{"--config", "segment.bytes=1073741824", "--config", "segment.ms=60000"})
+
+	actual := map[string]string{"segment.bytes": "1073741824", "segment.ms": "3600000"}
+	mods := diffConfig(desired, actual, isManagedConfigKey)
+	assertStringMap(t, "added on update", mods.ConfAdditions, map[string]string{"segment.ms": "60000"})
+	assertStringMap(t, "deleted on update", mods.ConfDeletions, map[string]string{})
+
+	mods = diffConfig(desiredTopicConfig(topicAttrs{}), actual, isManagedConfigKey)
+	assertStringMap(t, "deleted once unset", mods.ConfDeletions, actual)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// TopicLimits are the provider's guardrails on topics. A zero value means
+// no limit.
+type TopicLimits struct {
+	MaxPartitionsPerTopic int
+	MinReplicationFactor  int
+	MaxRetentionMs        int64
+	MaxTotalPartitions    int
+}
+
+// checkTopic validates a topic's settings against the limits, except for
+// max_total_partitions which needs the cluster's partition count.
+// retentionMs is the retention.ms override, or "" when the topic inherits the
+// broker default.
+func (l TopicLimits) checkTopic(partitions int, replicationFactor int, retentionMs string) error {
+	if l.MaxPartitionsPerTopic > 0 && partitions > l.MaxPartitionsPerTopic {
+		return fmt.Errorf("partitions: %d is above the provider's max_partitions_per_topic of %d", partitions, l.MaxPartitionsPerTopic)
+	}
+
+	if l.MinReplicationFactor > 0 && replicationFactor < l.MinReplicationFactor {
+		return fmt.Errorf("replication_factor: %d is below the provider's min_replication_factor of %d", replicationFactor, l.MinReplicationFactor)
+	}
+
+	if l.MaxRetentionMs > 0 && retentionMs != "" {
+		retention, err := strconv.ParseInt(retentionMs, 10, 64)
+		if err != nil {
+			return fmt.Errorf("retention.ms: '%s' is not a number", retentionMs)
+		}
+		if retention < 0 {
+			return fmt.Errorf("retention.ms: unlimited retention is not allowed by the provider's max_retention_ms of %d", l.MaxRetentionMs)
+		}
+		if retention > l.MaxRetentionMs {
+			return fmt.Errorf("retention.ms: %d is above the provider's max_retention_ms of %d", retention, l.MaxRetentionMs)
+		}
+	}
+
+	return nil
+}
+
+// checkTotalPartitions validates the cluster's partition count once a topic
+// goes from oldPartitions to newPartitions.
+func (l TopicLimits) checkTotalPartitions(clusterPartitions int, oldPartitions int, newPartitions int) error {
+	if l.MaxTotalPartitions <= 0 {
+		return nil
+	}
+
+	total := clusterPartitions - oldPartitions + newPartitions
+	if total > l.MaxTotalPartitions {
+		return fmt.Errorf("partitions: the cluster would have %d partitions, above the provider's max_total_partitions of %d", total, l.MaxTotalPartitions)
+	}
+	return nil
+}
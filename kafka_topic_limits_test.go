@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+func TestTopicLimits_checkTopic(t *testing.T) {
+	limits := TopicLimits{
+		MaxPartitionsPerTopic: 50,
+		MinReplicationFactor:  3,
+		MaxRetentionMs:        604800000,
+	}
+
+	if err := limits.checkTopic(50, 3, "604800000"); err != nil {
+		t.Errorf("expected topic within limits, got %s", err)
+	}
+	if err := limits.checkTopic(12, 3, ""); err != nil {
+		t.Errorf("expected broker default retention to be accepted, got %s", err)
+	}
+
+	for _, c := range []struct {
+		partitions        int
+		replicationFactor int
+		retentionMs       string
+	}{
+		{51, 3, ""},
+		{12, 2, ""},
+		{12, 3, "604800001"},
+		{12, 3, "-1"},
+	} {
+		if err := limits.checkTopic(c.partitions, c.replicationFactor, c.retentionMs); err == nil {
+			t.Errorf("expected %v to break the limits", c)
+		}
+	}
+
+	if err := (TopicLimits{}).checkTopic(10000, 1, "-1"); err != nil {
+		t.Errorf("expected no limits to accept anything, got %s", err)
+	}
+}
+
+func TestTopicLimits_checkTotalPartitions(t *testing.T) {
+	limits := TopicLimits{MaxTotalPartitions: 100}
+
+	if err := limits.checkTotalPartitions(90, 0, 10); err != nil {
+		t.Errorf("expected new topic within limits, got %s", err)
+	}
+	if err := limits.checkTotalPartitions(100, 10, 10); err != nil {
+		t.Errorf("expected unchanged topic within limits, got %s", err)
+	}
+	if err := limits.checkTotalPartitions(95, 5, 11); err == nil {
+		t.Errorf("expected growing topic to break the limit")
+	}
+}
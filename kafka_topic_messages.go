@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// tombstoneMarker stands for a null value in the console producer's input,
+// written as the value of the records deleting their key.
+const tombstoneMarker = "__terraform_kafka_tombstone__"
+
+// TopicRecord is a record written to a topic; a tombstone deletes its key
+// from a compacted topic.
+type TopicRecord struct {
+	Key       string
+	Value     string
+	Headers   map[string]string
+	Tombstone bool
+}
+
+// producerVersionPattern matches the version kafka-console-producer
+// --version prints, e.g. "3.2.0 (Commit:38103ffaa962ef50)".
+var producerVersionPattern = regexp.MustCompile(`(?m)^(\d+)\.(\d+)\.`)
+
+// produceRecords writes records to a topic with kafka-console-producer,
+// waiting for every in-sync replica to have them. Records with headers are
+// written in a run of their own, as they need the producer to parse
+// headers on every line.
+func (client *KafkaManagingClient) produceRecords(topic string, records []TopicRecord) error {
+	if client.ConsoleProducerScript == "" {
+		return fmt.Errorf("Unable to find kafka-console-producer to write records")
+	}
+	if client.BootstrapServers == "" {
+		return fmt.Errorf("Writing records needs bootstrap_servers")
+	}
+	if err := client.checkProducerFeatures(records); err != nil {
+		return err
+	}
+
+	var plain, withHeaders []TopicRecord
+	for _, r := range records {
+		if len(r.Headers) > 0 {
+			withHeaders = append(withHeaders, r)
+		} else {
+			plain = append(plain, r)
+		}
+	}
+
+	for _, batch := range [][]TopicRecord{plain, withHeaders} {
+		if len(batch) == 0 {
+			continue
+		}
+		headers := len(batch[0].Headers) > 0
+
+		cmd := exec.Command(client.ConsoleProducerScript, producerArgs(client.BootstrapServers, topic, headers, hasTombstones(batch))...)
+		cmd.Stdin = strings.NewReader(writeProducerInput(batch, headers))
+		if err := execCombinedCommand(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkProducerFeatures refuses tombstones and headers unless the console
+// producer is 3.2 or later: older ones ignore null.marker and
+// parse.headers, and would write the marker and headers as plain text.
+func (client *KafkaManagingClient) checkProducerFeatures(records []TopicRecord) error {
+	var needs []string
+	if hasTombstones(records) {
+		needs = append(needs, "tombstones")
+	}
+	for _, r := range records {
+		if len(r.Headers) > 0 {
+			needs = append(needs, "headers")
+			break
+		}
+	}
+	if len(needs) == 0 {
+		return nil
+	}
+
+	out, err := exec.Command(client.ConsoleProducerScript, "--version").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("Writing %s needs kafka-console-producer 3.2 or later, and %s does not report a version", strings.Join(needs, " and "), client.ConsoleProducerScript)
+	}
+	major, minor, ok := parseProducerVersion(string(out))
+	if !ok || major < 3 || (major == 3 && minor < 2) {
+		return fmt.Errorf("Writing %s needs kafka-console-producer 3.2 or later, found %s", strings.Join(needs, " and "), strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// parseProducerVersion reads the major and minor version out of the
+// output of --version.
+func parseProducerVersion(output string) (int, int, bool) {
+	m := producerVersionPattern.FindStringSubmatch(output)
+	if m == nil {
+		return 0, 0, false
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	return major, minor, true
+}
+
+func hasTombstones(records []TopicRecord) bool {
+	for _, r := range records {
+		if r.Tombstone {
+			return true
+		}
+	}
+	return false
+}
+
+// producerArgs only asks for null.marker and parse.headers when the batch
+// needs them, so that plain records can be written by producers older
+// than 3.2.
+func producerArgs(brokers string, topic string, headers bool, tombstones bool) []string {
+	args := []string{
+		"--broker-list", brokers,
+		"--topic", topic,
+		"--producer-property", "acks=all",
+		"--property", "parse.key=true",
+		"--property", "key.separator=\t",
+	}
+	if tombstones {
+		args = append(args, "--property", "null.marker="+tombstoneMarker)
+	}
+	if headers {
+		args = append(args,
+			"--property", "parse.headers=true",
+			"--property", "headers.delimiter=\t",
+			"--property", "headers.separator=,",
+			"--property", "headers.key.separator=:")
+	}
+	return args
+}
+
+// writeProducerInput writes one line per record, as the console producer
+// parses them: headers if any, key and value separated by tabs.
+func writeProducerInput(records []TopicRecord, headers bool) string {
+	var lines []string
+	for _, r := range records {
+		var line string
+		if headers {
+			var pairs []string
+			for _, k := range sortedKeys(r.Headers) {
+				pairs = append(pairs, k+":"+r.Headers[k])
+			}
+			line = strings.Join(pairs, ",") + "\t"
+		}
+		value := r.Value
+		if r.Tombstone {
+			value = tombstoneMarker
+		}
+		lines = append(lines, line+r.Key+"\t"+value+"\n")
+	}
+	return strings.Join(lines, "")
+}
+
+// recordChanges returns the records to write to go from the records written
+// before to the desired ones: the new or changed ones, and tombstones for
+// the keys that are gone.
+func recordChanges(written map[string]TopicRecord, desired map[string]TopicRecord) []TopicRecord {
+	var changes []TopicRecord
+	for _, k := range sortedRecordKeys(desired) {
+		o, ok := written[k]
+		if !ok || !sameRecord(o, desired[k]) {
+			changes = append(changes, desired[k])
+		}
+	}
+	for _, k := range sortedRecordKeys(written) {
+		if _, ok := desired[k]; !ok {
+			changes = append(changes, TopicRecord{Key: k, Tombstone: true})
+		}
+	}
+	return changes
+}
+
+func sameRecord(a TopicRecord, b TopicRecord) bool {
+	if a.Value != b.Value || len(a.Headers) != len(b.Headers) {
+		return false
+	}
+	for k, v := range a.Headers {
+		if bv, ok := b.Headers[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedRecordKeys(m map[string]TopicRecord) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// checkRecordText refuses the characters the console producer would take
+// for separators, and the tombstone marker.
+func checkRecordText(what string, s string, separators string) error {
+	if strings.ContainsAny(s, "\t\n\r"+separators) {
+		return fmt.Errorf("%s '%s' must not contain tabs, line breaks or any of '%s'", what, s, separators)
+	}
+	if s == tombstoneMarker {
+		return fmt.Errorf("%s must not be %s, which stands for tombstones", what, tombstoneMarker)
+	}
+	return nil
+}
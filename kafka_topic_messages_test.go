@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRecordChanges(t *testing.T) {
+	written := map[string]TopicRecord{
+		"a": {Key: "a", Value: "1"},
+		"b": {Key: "b", Value: "2", Headers: map[string]string{"source": "seed"}},
+		"c": {Key: "c", Value: "3"},
+	}
+	desired := map[string]TopicRecord{
+		"a": {Key: "a", Value: "1"},
+		"b": {Key: "b", Value: "2", Headers: map[string]string{"source": "import"}},
+		"d": {Key: "d", Value: "4"},
+	}
+
+	changes := recordChanges(written, desired)
+	var keys []string
+	for _, r := range changes {
+		keys = append(keys, r.Key)
+	}
+	assertStrings(t, "changed keys", keys, []string{"b", "d", "c"})
+	if !changes[2].Tombstone || changes[0].Tombstone || changes[1].Tombstone {
+		t.Errorf("expected only the removed key to be tombstoned, got %v", changes)
+	}
+}
+
+func TestWriteProducerInput(t *testing.T) {
+	records := []TopicRecord{
+		{Key: "a", Value: "1"},
+		{Key: "c", Tombstone: true},
+	}
+	if input := writeProducerInput(records, false); input != "a\t1\nc\t"+tombstoneMarker+"\n" {
+		t.Errorf("unexpected input %q", input)
+	}
+
+	records = []TopicRecord{{Key: "b", Value: "2", Headers: map[string]string{"v": "1", "source": "seed"}}}
+	if input := writeProducerInput(records, true); input != "source:seed,v:1\tb\t2\n" {
+		t.Errorf("unexpected input %q", input)
+	}
+}
+
+func TestCheckRecordText(t *testing.T) {
+	if err := checkRecordText("value", `{"enabled": true}`, ""); err != nil {
+		t.Errorf("unexpected error %s", err)
+	}
+	for _, s := range []string{"a\tb", "a\nb", tombstoneMarker} {
+		if err := checkRecordText("value", s, ""); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+	if err := checkRecordText("header", "a:b", ",:"); err == nil {
+		t.Errorf("expected an error for a header holding a separator")
+	}
+}
+
+func TestProducerArgs(t *testing.T) {
+	assertStrings(t, "plain producer args", producerArgs("b:9092", "flags", false, false), []string{
+		"--broker-list", "b:9092", "--topic", "flags", "--producer-property", "acks=all",
+		"--property", "parse.key=true", "--property", "key.separator=\t",
+	})
+
+	args := producerArgs("b:9092", "flags", false, true)
+	if args[len(args)-1] != "null.marker="+tombstoneMarker {
+		t.Errorf("expected a null.marker for tombstones, got %v", args)
+	}
+}
+
+func TestParseProducerVersion(t *testing.T) {
+	for output, expected := range map[string][2]int{
+		"3.2.0 (Commit:38103ffaa962ef50)\n": {3, 2},
+		"2.8.1 (Commit:839b886f9b732b15)\n": {2, 8},
+	} {
+		major, minor, ok := parseProducerVersion(output)
+		if !ok || major != expected[0] || minor != expected[1] {
+			t.Errorf("expected %v for %q, got %d.%d", expected, output, major, minor)
+		}
+	}
+	if _, _, ok := parseProducerVersion("--version is not a recognized option"); ok {
+		t.Errorf("expected no version from an unrecognized option")
+	}
+}
+
+// recordChange stands in for a kafka_topic_messages resource being updated.
+type recordChange struct {
+	old, new []interface{}
+	partial  bool
+	saved    []string
+}
+
+func (r *recordChange) GetChange(key string) (interface{}, interface{}) { return r.old, r.new }
+func (r *recordChange) Partial(on bool)                                 { r.partial = on }
+func (r *recordChange) SetPartial(key string)                           { r.saved = append(r.saved, key) }
+
+func TestUpdateTopicRecords(t *testing.T) {
+	change := func() *recordChange {
+		return &recordChange{
+			old: []interface{}{map[string]interface{}{"key": "a", "value": "1"}},
+			new: []interface{}{map[string]interface{}{"key": "b", "value": "2"}},
+		}
+	}
+
+	failed := change()
+	err := updateTopicRecords(failed, func(records []TopicRecord) error {
+		return fmt.Errorf("broker unavailable")
+	})
+	if err == nil {
+		t.Fatalf("expected the write error to be returned")
+	}
+	if !failed.partial || len(failed.saved) > 0 {
+		t.Errorf("expected the new records to stay out of state, got partial=%t saved=%v", failed.partial, failed.saved)
+	}
+
+	written := change()
+	var keys []string
+	err = updateTopicRecords(written, func(records []TopicRecord) error {
+		for _, r := range records {
+			keys = append(keys, r.Key)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStrings(t, "written keys", keys, []string{"b", "a"})
+	if written.partial || len(written.saved) != 1 {
+		t.Errorf("expected the new records to be saved, got partial=%t saved=%v", written.partial, written.saved)
+	}
+}
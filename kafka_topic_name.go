@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TopicNamePolicy is the provider's naming convention for topics: a prefix
+// put in front of every kafka_topic name and a pattern the resulting name
+// must match.
+type TopicNamePolicy struct {
+	Prefix  string
+	Pattern *regexp.Regexp
+}
+
+// fullName returns the name of the topic in Kafka for a kafka_topic name.
+func (p TopicNamePolicy) fullName(name string) string {
+	return p.Prefix + name
+}
+
+// check validates a full topic name against Kafka's rules and the pattern.
+func (p TopicNamePolicy) check(fullName string) error {
+	if err := checkTopicName(fullName); err != nil {
+		return err
+	}
+	if p.Pattern != nil && !p.Pattern.MatchString(fullName) {
+		return fmt.Errorf("topic name '%s' does not match the provider's topic_name_pattern %s", fullName, p.Pattern)
+	}
+	return nil
+}
+
+// compileTopicNamePattern compiles a topic_name_pattern so that it has to
+// match the whole topic name.
+func compileTopicNamePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+// maxTopicNameLength is the longest topic name Kafka accepts.
+const maxTopicNameLength = 249
+
+var legalTopicNameR = regexp.MustCompile("^[a-zA-Z0-9._-]+$")
+
+// checkTopicName applies the rules Kafka enforces on topic names.
+func checkTopicName(name string) error {
+	if name == "" {
+		return fmt.Errorf("topic name is empty")
+	}
+	if name == "." || name == ".." {
+		return fmt.Errorf("topic name cannot be '%s'", name)
+	}
+	if len(name) > maxTopicNameLength {
+		return fmt.Errorf("topic name '%s' is %d characters long, the maximum is %d", name, len(name), maxTopicNameLength)
+	}
+	if !legalTopicNameR.MatchString(name) {
+		return fmt.Errorf("topic name '%s' is illegal, it may only contain ASCII letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// hasMetricNameRisk reports whether a topic name contains a period or an
+// underscore, which Kafka maps to the same character in metric names.
+func hasMetricNameRisk(name string) bool {
+	return strings.ContainsAny(name, "._")
+}
+
+// metricTopicName returns the name a topic is known by in metrics.
+func metricTopicName(name string) string {
+	return strings.Replace(name, ".", "_", -1)
+}
+
+// collidingTopic returns the first of existing, other than name itself, that
+// would share name's metric name, or "" if there is none.
+func collidingTopic(name string, existing []string) string {
+	for _, t := range existing {
+		if t != name && metricTopicName(t) == metricTopicName(name) {
+			return t
+		}
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckTopicName(t *testing.T) {
+	for _, name := range []string{"orders", "team.orders_v1-2", strings.Repeat("a", maxTopicNameLength)} {
+		if err := checkTopicName(name); err != nil {
+			t.Errorf("expected '%s' to be legal, got %s", name, err)
+		}
+	}
+
+	for _, name := range []string{"", ".", "..", "orders/v1", "orders v1", "ordérs", strings.Repeat("a", maxTopicNameLength+1)} {
+		if err := checkTopicName(name); err == nil {
+			t.Errorf("expected '%s' to be illegal", name)
+		}
+	}
+}
+
+func TestCollidingTopic(t *testing.T) {
+	existing := []string{"payments", "team_orders", "team.orders"}
+
+	assertString(t, "collision", collidingTopic("team.orders", existing), "team_orders")
+	assertString(t, "collision", collidingTopic("team_orders", []string{"team_orders"}), "")
+	assertString(t, "collision", collidingTopic("orders", existing), "")
+}
+
+func TestTopicNamePolicy(t *testing.T) {
+	pattern, err := compileTopicNamePattern("[a-z]+\\.[a-z]+\\.[a-z_]+\\.v[0-9]+")
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := TopicNamePolicy{Prefix: "payments.", Pattern: pattern}
+
+	assertString(t, "fullName", policy.fullName("cards.authorised.v1"), "payments.cards.authorised.v1")
+
+	if err := policy.check(policy.fullName("cards.authorised.v1")); err != nil {
+		t.Errorf("expected name to match, got %s", err)
+	}
+	for _, name := range []string{"cards.authorised", "cards.authorised.v1.extra", "Cards.authorised.v1"} {
+		if err := policy.check(policy.fullName(name)); err == nil {
+			t.Errorf("expected '%s' to be rejected", policy.fullName(name))
+		}
+	}
+
+	if err := (TopicNamePolicy{}).check("anything-goes"); err != nil {
+		t.Errorf("expected no pattern to accept any legal name, got %s", err)
+	}
+}
+
+func TestValidateTopicName(t *testing.T) {
+	if warnings, errs := validateTopicName("orders-v1", "name"); len(warnings) > 0 || len(errs) > 0 {
+		t.Errorf("unexpected warnings %v and errors %v", warnings, errs)
+	}
+	if warnings, errs := validateTopicName("orders.v1", "name"); len(warnings) != 1 || len(errs) > 0 {
+		t.Errorf("expected a warning, got %v and errors %v", warnings, errs)
+	}
+	if _, errs := validateTopicName("orders v1", "name"); len(errs) == 0 {
+		t.Errorf("expected an error for a space")
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type unit struct {
+	name   string
+	factor int64
+}
+
+// durationUnits are listed from the largest to the smallest, the order in
+// which formatDuration tries them. Weeks are accepted but never produced, so
+// a week reads as "7d".
+var durationUnits = []unit{
+	{"d", 24 * 60 * 60 * 1000},
+	{"h", 60 * 60 * 1000},
+	{"m", 60 * 1000},
+	{"s", 1000},
+	{"ms", 1},
+}
+
+var durationInputUnits = append([]unit{{"w", 7 * 24 * 60 * 60 * 1000}}, durationUnits...)
+
+// sizeUnits are listed in the order formatSize tries them: binary units
+// first, then decimal ones.
+var sizeUnits = []unit{
+	{"TiB", 1 << 40},
+	{"GiB", 1 << 30},
+	{"MiB", 1 << 20},
+	{"KiB", 1 << 10},
+	{"TB", 1000 * 1000 * 1000 * 1000},
+	{"GB", 1000 * 1000 * 1000},
+	{"MB", 1000 * 1000},
+	{"kB", 1000},
+	{"B", 1},
+}
+
+var quantityR = regexp.MustCompile("^\\s*(\\d+)\\s*([a-zA-Z]+)\\s*$")
+
+// parseDuration reads a duration such as "7d" or "90m" as milliseconds.
+func parseDuration(s string) (int64, error) {
+	return parseQuantity(s, durationInputUnits, false)
+}
+
+// formatDuration writes milliseconds in the largest unit dividing them.
+func formatDuration(ms int64) string {
+	return formatQuantity(ms, durationUnits)
+}
+
+// parseSize reads a size such as "1GiB" or "500MB" as bytes. Unit case is
+// ignored, so "1gib" and "1KB" are accepted too.
+func parseSize(s string) (int64, error) {
+	return parseQuantity(s, sizeUnits, true)
+}
+
+// formatSize writes bytes in the largest unit dividing them, preferring
+// binary units.
+func formatSize(b int64) string {
+	return formatQuantity(b, sizeUnits)
+}
+
+func parseQuantity(s string, units []unit, ignoreCase bool) (int64, error) {
+	m := quantityR.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("'%s' is not a number followed by a unit", s)
+	}
+
+	n, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("'%s': %s", s, err)
+	}
+
+	var names []string
+	for _, u := range units {
+		if u.name == m[2] || (ignoreCase && strings.EqualFold(u.name, m[2])) {
+			if n > (1<<63-1)/u.factor {
+				return 0, fmt.Errorf("'%s' is too large", s)
+			}
+			return n * u.factor, nil
+		}
+		names = append(names, u.name)
+	}
+
+	return 0, fmt.Errorf("'%s' has unknown unit '%s', expected one of %s", s, m[2], strings.Join(names, ", "))
+}
+
+func formatQuantity(n int64, units []unit) string {
+	for _, u := range units {
+		if n != 0 && n%u.factor == 0 {
+			return strconv.FormatInt(n/u.factor, 10) + u.name
+		}
+	}
+	return strconv.FormatInt(n, 10) + units[len(units)-1].name
+}
@@ -0,0 +1,66 @@
+package main
+
+import "testing"
+
+func TestParseDuration(t *testing.T) {
+	for s, expected := range map[string]int64{
+		"7d":     604800000,
+		"1w":     604800000,
+		"90m":    5400000,
+		"1 h":    3600000,
+		"250ms":  250,
+		"0s":     0,
+		" 30s  ": 30000,
+	} {
+		v, err := parseDuration(s)
+		if err != nil {
+			t.Errorf("unexpected error for '%s': %s", s, err)
+			continue
+		}
+		assertInt64(t, s, v, expected)
+	}
+
+	for _, s := range []string{"", "7", "d", "7 days", "1.5h", "-1d", "7D"} {
+		if _, err := parseDuration(s); err == nil {
+			t.Errorf("expected '%s' to be rejected", s)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	assertString(t, "604800000", formatDuration(604800000), "7d")
+	assertString(t, "5400000", formatDuration(5400000), "90m")
+	assertString(t, "1500", formatDuration(1500), "1500ms")
+	assertString(t, "0", formatDuration(0), "0ms")
+}
+
+func TestParseSize(t *testing.T) {
+	for s, expected := range map[string]int64{
+		"1GiB":  1073741824,
+		"1gib":  1073741824,
+		"512MB": 512000000,
+		"1KB":   1000,
+		"10B":   10,
+	} {
+		v, err := parseSize(s)
+		if err != nil {
+			t.Errorf("unexpected error for '%s': %s", s, err)
+			continue
+		}
+		assertInt64(t, s, v, expected)
+	}
+
+	for _, s := range []string{"1", "1XB", "1.5GiB", "99999999999TiB"} {
+		if _, err := parseSize(s); err == nil {
+			t.Errorf("expected '%s' to be rejected", s)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	assertString(t, "1073741824", formatSize(1073741824), "1GiB")
+	assertString(t, "1000000000", formatSize(1000000000), "1GB")
+	assertString(t, "1536", formatSize(1536), "1536B")
+	assertString(t, "3072", formatSize(3072), "3KiB")
+	assertString(t, "1023", formatSize(1023), "1023B")
+}
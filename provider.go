@@ -1,9 +1,11 @@
 package main
 
 import (
+  "log"
   "os/exec"
   "strings"
   "github.com/hashicorp/terraform/helper/schema"
+  "github.com/hashicorp/terraform/helper/validation"
   "github.com/hashicorp/terraform/terraform"
   "os"
   "fmt"
@@ -26,10 +28,136 @@ func Provider() terraform.ResourceProvider {
         Required:    true,
         Description: providerName + " Zookeeper address (<host>:[<port>])",
       },
+      "bootstrap_servers": &schema.Schema{
+        Type:        schema.TypeString,
+        Optional:    true,
+        Default:     "",
+        Description: providerName + " Broker addresses (<host>:<port>[,<host>:<port>]) for tools that talk to the brokers directly",
+      },
+      "topic_name_prefix": &schema.Schema{
+        Type:        schema.TypeString,
+        Optional:    true,
+        Default:     "",
+        Description: providerName + " Prefix put in front of every kafka_topic name",
+      },
+      "topic_name_pattern": &schema.Schema{
+        Type:         schema.TypeString,
+        Optional:     true,
+        Default:      "",
+        Description:  providerName + " Regular expression every full topic name must match",
+        ValidateFunc: validation.ValidateRegexp,
+      },
+      "max_partitions_per_topic": &schema.Schema{
+        Type:         schema.TypeInt,
+        Optional:     true,
+        Default:      0,
+        Description:  providerName + " Most partitions a topic may have, 0 for no limit",
+        ValidateFunc: validation.IntAtLeast(0),
+      },
+      "min_replication_factor": &schema.Schema{
+        Type:         schema.TypeInt,
+        Optional:     true,
+        Default:      0,
+        Description:  providerName + " Lowest replication factor a topic may have, 0 for no limit",
+        ValidateFunc: validation.IntAtLeast(0),
+      },
+      "max_retention_ms": &schema.Schema{
+        Type:         schema.TypeInt,
+        Optional:     true,
+        Default:      0,
+        Description:  providerName + " Longest retention.ms a topic may have, 0 for no limit",
+        ValidateFunc: validation.IntAtLeast(0),
+      },
+      "max_total_partitions": &schema.Schema{
+        Type:         schema.TypeInt,
+        Optional:     true,
+        Default:      0,
+        Description:  providerName + " Most partitions the whole cluster may have, 0 for no limit",
+        ValidateFunc: validation.IntAtLeast(0),
+      },
+      "console_producer_path": &schema.Schema{
+        Type:        schema.TypeString,
+        Optional:    true,
+        Default:     "",
+        Description: providerName + " Path to a kafka-console-producer to use instead of the one in kafka_bin_path",
+      },
+      "schema_registry_url": &schema.Schema{
+        Type:        schema.TypeString,
+        Optional:    true,
+        Default:     "",
+        Description: providerName + " Schema Registry URL, needed by kafka_schema_registry_subject",
+      },
+      "schema_registry_username": &schema.Schema{
+        Type:        schema.TypeString,
+        Optional:    true,
+        Default:     "",
+        Description: providerName + " Schema Registry basic auth username",
+      },
+      "schema_registry_password": &schema.Schema{
+        Type:        schema.TypeString,
+        Optional:    true,
+        Default:     "",
+        Sensitive:   true,
+        Description: providerName + " Schema Registry basic auth password",
+      },
+      "connect_url": &schema.Schema{
+        Type:        schema.TypeString,
+        Optional:    true,
+        Default:     "",
+        Description: providerName + " Kafka Connect REST API URL, needed by kafka_connect_connector",
+      },
+      "connect_username": &schema.Schema{
+        Type:        schema.TypeString,
+        Optional:    true,
+        Default:     "",
+        Description: providerName + " Kafka Connect basic auth username",
+      },
+      "connect_password": &schema.Schema{
+        Type:        schema.TypeString,
+        Optional:    true,
+        Default:     "",
+        Sensitive:   true,
+        Description: providerName + " Kafka Connect basic auth password",
+      },
+      "default_topic_config": &schema.Schema{
+        Type:        schema.TypeList,
+        Optional:    true,
+        MaxItems:    1,
+        Description: providerName + " Settings applied to every kafka_topic beneath its own",
+        Elem: &schema.Resource{
+          Schema: map[string]*schema.Schema{
+            "replication_factor": &schema.Schema{
+              Type:        schema.TypeInt,
+              Optional:    true,
+              Default:     0,
+              Description: "replication factor of topics not setting one",
+            },
+            "config": &schema.Schema{
+              Type:        schema.TypeMap,
+              Optional:    true,
+              Description: "topic-level config overrides, keyed by Kafka config name",
+            },
+          },
+        },
+      },
     },
     
     ResourcesMap: map[string]*schema.Resource{
-      "kafka_topic": resourceKafkaTopic(),
+      "kafka_topic":                   resourceKafkaTopic(),
+      "kafka_acl":                     resourceKafkaAcl(),
+      "kafka_quota":                   resourceKafkaQuota(),
+      "kafka_user_scram_credential":   resourceKafkaUserScramCredential(),
+      "kafka_broker_config":           resourceKafkaBrokerConfig(),
+      "kafka_consumer_group_offsets":  resourceKafkaConsumerGroupOffsets(),
+      "kafka_partition_reassignment":  resourceKafkaPartitionReassignment(),
+      "kafka_replication_throttle":    resourceKafkaReplicationThrottle(),
+      "kafka_schema_registry_subject": resourceKafkaSchemaRegistrySubject(),
+      "kafka_connect_connector":       resourceKafkaConnectConnector(),
+      "kafka_topic_messages":          resourceKafkaTopicMessages(),
+    },
+
+    DataSourcesMap: map[string]*schema.Resource{
+      "kafka_expired_topics": dataSourceKafkaExpiredTopics(),
     },
 
     ConfigureFunc: providerConfigure,
@@ -48,7 +176,49 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
   client.ConfigScript, err = scriptPath(prefixPath, "kafka-configs", "kafka-configs.sh")
   if err != nil { return nil, err }
 
+  client.ZookeeperShellScript = optionalScriptPath(prefixPath, "zookeeper-shell", "zookeeper-shell.sh")
+  client.LeaderElectionScript = optionalScriptPath(prefixPath, "kafka-leader-election", "kafka-leader-election.sh")
+  client.PreferredReplicaElectionScript = optionalScriptPath(prefixPath, "kafka-preferred-replica-election", "kafka-preferred-replica-election.sh")
+  client.ConsumerGroupsScript = optionalScriptPath(prefixPath, "kafka-consumer-groups", "kafka-consumer-groups.sh")
+  client.ReassignPartitionsScript = optionalScriptPath(prefixPath, "kafka-reassign-partitions", "kafka-reassign-partitions.sh")
+  client.ConsoleProducerScript = optionalScriptPath(prefixPath, "kafka-console-producer", "kafka-console-producer.sh")
+  if producerPath := d.Get("console_producer_path").(string); producerPath != "" {
+    client.ConsoleProducerScript = producerPath
+  }
+
   client.Zookeeper = d.Get("zookeeper").(string)
+  client.BootstrapServers = d.Get("bootstrap_servers").(string)
+
+  client.Acls = &KafkaAclClient{
+    Zookeeper:        client.Zookeeper,
+    BootstrapServers: client.BootstrapServers,
+    AclScript:        optionalScriptPath(prefixPath, "kafka-acls", "kafka-acls.sh"),
+  }
+
+  client.TopicNamePolicy.Prefix = d.Get("topic_name_prefix").(string)
+  client.TopicNamePolicy.Pattern, err = compileTopicNamePattern(d.Get("topic_name_pattern").(string))
+  if err != nil { return nil, fmt.Errorf("Invalid topic_name_pattern: %s", err) }
+
+  client.TopicLimits = TopicLimits{
+    MaxPartitionsPerTopic: d.Get("max_partitions_per_topic").(int),
+    MinReplicationFactor:  d.Get("min_replication_factor").(int),
+    MaxRetentionMs:        int64(d.Get("max_retention_ms").(int)),
+    MaxTotalPartitions:    d.Get("max_total_partitions").(int),
+  }
+
+  if registryURL := d.Get("schema_registry_url").(string); registryURL != "" {
+    client.SchemaRegistry = newSchemaRegistryClient(registryURL, d.Get("schema_registry_username").(string), d.Get("schema_registry_password").(string))
+  }
+
+  if connectURL := d.Get("connect_url").(string); connectURL != "" {
+    client.Connect = newKafkaConnectClient(connectURL, d.Get("connect_username").(string), d.Get("connect_password").(string))
+  }
+
+  if raw := d.Get("default_topic_config").([]interface{}); len(raw) > 0 && raw[0] != nil {
+    defaults := raw[0].(map[string]interface{})
+    client.TopicDefaults.ReplicationFactor = defaults["replication_factor"].(int)
+    client.TopicDefaults.Config = toStringMap(defaults["config"])
+  }
 
   return client, nil
 }
@@ -78,6 +248,17 @@ func scriptPath(prefixPath string, scriptNames ... string) (string, error) {
   return "", fmt.Errorf("None of these scripts exist %s", scriptNames)
 }
 
+// optionalScriptPath finds a script only some features need, returning ""
+// when it is missing so that the features fail instead of the provider.
+func optionalScriptPath(prefixPath string, scriptNames ... string) string {
+  path, err := scriptPath(prefixPath, scriptNames...)
+  if err != nil {
+    log.Printf("[DEBUG] %s", err)
+    return ""
+  }
+  return path
+}
+
 func which(executables ... string) (string, error) {
   for _, executable := range executables {
     cmd := exec.Command("which", executable)
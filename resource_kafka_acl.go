@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func resourceKafkaAcl() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKafkaAclCreate,
+		Read:   resourceKafkaAclRead,
+		Delete: resourceKafkaAclDelete,
+
+		CustomizeDiff: resourceKafkaAclCustomizeDiff,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceKafkaAclImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"principal": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "principal the ACL applies to, such as User:alice",
+			},
+			"host": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "host the principal connects from, * for any",
+				Default:     "*",
+			},
+			"operation": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "operation allowed or denied, such as Read, Write or DescribeConfigs",
+				ValidateFunc: validation.StringInSlice([]string{
+					"All", "Read", "Write", "Create", "Delete", "Alter", "Describe",
+					"ClusterAction", "DescribeConfigs", "AlterConfigs", "IdempotentWrite",
+				}, false),
+			},
+			"permission_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Description:  "Allow or Deny",
+				Default:      "Allow",
+				ValidateFunc: validation.StringInSlice([]string{"Allow", "Deny"}, false),
+			},
+			"resource_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "Topic, Group, Cluster, TransactionalId or DelegationToken",
+				ValidateFunc: validation.StringInSlice([]string{"Topic", "Group", "Cluster", "TransactionalId", "DelegationToken"}, false),
+			},
+			"resource_name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "name of the resource, or its prefix for prefixed patterns; kafka-cluster for the Cluster resource type",
+			},
+			"pattern_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Description:  "literal, or prefixed to match every resource whose name starts with resource_name",
+				Default:      aclPatternLiteral,
+				ValidateFunc: validation.StringInSlice([]string{aclPatternLiteral, aclPatternPrefixed}, false),
+			},
+		},
+	}
+}
+
+func resourceKafkaAclCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient).Acls
+	acl := buildKafkaAcl(d)
+
+	if err := client.createAcl(acl); err != nil {
+		return err
+	}
+
+	d.SetId(acl.id())
+	return resourceKafkaAclRead(d, meta)
+}
+
+func resourceKafkaAclRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient).Acls
+	acl, err := parseAclID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	entries, err := client.listAcls(acl)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if e.matches(acl.AclEntry) {
+			return nil
+		}
+	}
+
+	log.Printf("[WARN] ACL %s not found, removing from state", acl)
+	d.SetId("")
+	return nil
+}
+
+func resourceKafkaAclDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient).Acls
+	acl, err := parseAclID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	if err := client.deleteAcl(acl); err != nil {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// resourceKafkaAclImport fills the attributes from an ID of the form
+// principal|host|operation|permission_type|resource_type|pattern_type|resource_name.
+func resourceKafkaAclImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	acl, err := parseAclID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	d.Set("principal", acl.Principal)
+	d.Set("host", acl.Host)
+	d.Set("operation", acl.Operation)
+	d.Set("permission_type", acl.PermissionType)
+	d.Set("resource_type", acl.ResourceType)
+	d.Set("pattern_type", acl.PatternType)
+	d.Set("resource_name", acl.ResourceName)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceKafkaAclCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Get("resource_type").(string) == "Cluster" {
+		if name := d.Get("resource_name").(string); name != aclClusterName {
+			return fmt.Errorf("resource_name must be %s for the Cluster resource type, not '%s'", aclClusterName, name)
+		}
+		if d.Get("pattern_type").(string) != aclPatternLiteral {
+			return fmt.Errorf("pattern_type must be %s for the Cluster resource type", aclPatternLiteral)
+		}
+	}
+	return nil
+}
+
+func buildKafkaAcl(d *schema.ResourceData) *KafkaAcl {
+	return &KafkaAcl{
+		AclEntry: AclEntry{
+			Principal:      d.Get("principal").(string),
+			Host:           d.Get("host").(string),
+			Operation:      d.Get("operation").(string),
+			PermissionType: d.Get("permission_type").(string),
+		},
+		ResourceType: d.Get("resource_type").(string),
+		ResourceName: d.Get("resource_name").(string),
+		PatternType:  d.Get("pattern_type").(string),
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceKafkaBrokerConfig() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKafkaBrokerConfigCreate,
+		Read:   resourceKafkaBrokerConfigRead,
+		Update: resourceKafkaBrokerConfigUpdate,
+		Delete: resourceKafkaBrokerConfigDelete,
+
+		CustomizeDiff: resourceKafkaBrokerConfigCustomizeDiff,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceKafkaBrokerConfigImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"broker_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "broker whose config to manage, or empty for the cluster default",
+				Default:     "",
+			},
+			"config": &schema.Schema{
+				Type:        schema.TypeMap,
+				Required:    true,
+				Description: "dynamic broker configs, keyed by Kafka config name",
+			},
+			"static_config": &schema.Schema{
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "configs the broker took from server.properties, known with bootstrap_servers for a broker_id",
+			},
+		},
+	}
+}
+
+func resourceKafkaBrokerConfigCreate(d *schema.ResourceData, meta interface{}) error {
+	brokerID := d.Get("broker_id").(string)
+	if err := applyBrokerConfig(d, meta); err != nil {
+		return err
+	}
+
+	d.SetId(brokerEntity(brokerID)[0].Name)
+	return resourceKafkaBrokerConfigRead(d, meta)
+}
+
+func resourceKafkaBrokerConfigUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := applyBrokerConfig(d, meta); err != nil {
+		return err
+	}
+	return resourceKafkaBrokerConfigRead(d, meta)
+}
+
+// applyBrokerConfig converges the dynamic config of the entity, deleting
+// only the keys this resource declared before so that configs set by
+// others, such as replication throttles, are left alone.
+func applyBrokerConfig(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+	entity := brokerEntity(d.Get("broker_id").(string))
+
+	actual, err := client.describeEntityConfig(entity)
+	if err != nil {
+		return err
+	}
+
+	o, n := d.GetChange("config")
+	oldDeclared := toStringMap(o)
+	mods := diffConfig(toStringMap(n), actual, func(key string) bool {
+		_, ok := oldDeclared[key]
+		return ok
+	})
+	if mods.empty() {
+		return nil
+	}
+
+	return client.alterEntityConfig(entity, mods)
+}
+
+func resourceKafkaBrokerConfigRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+	entity := brokerEntity(d.Id())
+	if d.Id() == entityDefault {
+		d.Set("broker_id", "")
+	} else {
+		d.Set("broker_id", d.Id())
+	}
+
+	actual, err := client.describeEntityConfig(entity)
+	if err != nil {
+		return err
+	}
+
+	// On import nothing is declared yet and every dynamic config is taken in.
+	declared := toStringMap(d.Get("config"))
+	conf := make(map[string]string)
+	for k, v := range actual {
+		if _, ok := declared[k]; ok || len(declared) == 0 {
+			conf[k] = v
+		}
+	}
+	// Sensitive configs read back as null, so their declared value is kept.
+	for k, v := range conf {
+		if v == "null" && declared[k] != "" {
+			conf[k] = declared[k]
+		}
+	}
+	d.Set("config", conf)
+
+	if client.BootstrapServers != "" && d.Id() != entityDefault {
+		static, sErr := client.describeStaticBrokerConfig(d.Id())
+		if sErr != nil {
+			log.Printf("[WARN] Unable to read static config of broker %s: %v", d.Id(), sErr)
+		} else {
+			d.Set("static_config", static)
+		}
+	}
+
+	return nil
+}
+
+func resourceKafkaBrokerConfigDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+	entity := brokerEntity(d.Id())
+
+	actual, err := client.describeEntityConfig(entity)
+	if err != nil {
+		return err
+	}
+
+	declared := toStringMap(d.Get("config"))
+	mods := diffConfig(map[string]string{}, actual, func(key string) bool {
+		_, ok := declared[key]
+		return ok
+	})
+	if !mods.empty() {
+		if err := client.alterEntityConfig(entity, mods); err != nil {
+			return err
+		}
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// resourceKafkaBrokerConfigImport takes a broker id, or <default> for the
+// cluster default.
+func resourceKafkaBrokerConfigImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if d.Id() == "" {
+		return nil, fmt.Errorf("Expected a broker id or %s to import", entityDefault)
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceKafkaBrokerConfigCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	conf := toStringMap(d.Get("config"))
+	return checkBrokerConfig(conf, d.Get("broker_id").(string) == "")
+}
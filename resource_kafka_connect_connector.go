@@ -0,0 +1,292 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceKafkaConnectConnector() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKafkaConnectConnectorCreate,
+		Read:   resourceKafkaConnectConnectorRead,
+		Update: resourceKafkaConnectConnectorUpdate,
+		Delete: resourceKafkaConnectConnectorDelete,
+
+		CustomizeDiff: resourceKafkaConnectConnectorCustomizeDiff,
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "name of the connector",
+			},
+			"config": &schema.Schema{
+				Type:        schema.TypeMap,
+				Required:    true,
+				Description: "connector config, including connector.class",
+			},
+			"sensitive_config": &schema.Schema{
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "connector config kept out of plan output, such as passwords",
+			},
+			"paused": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "pause the connector and its tasks",
+				Default:     false,
+			},
+			"type": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "source or sink",
+			},
+			"state": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "state of the connector: RUNNING, PAUSED, FAILED or UNASSIGNED",
+			},
+			"worker_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "worker running the connector",
+			},
+			"task": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "state of each task of the connector",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"state": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"worker_id": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"trace": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func kafkaConnect(meta interface{}) (*KafkaConnectClient, error) {
+	client := meta.(*KafkaManagingClient).Connect
+	if client == nil {
+		return nil, fmt.Errorf("Managing connectors needs the provider's connect_url")
+	}
+	return client, nil
+}
+
+// retryConnect retries a call while the Connect workers are rebalancing.
+func retryConnect(timeout time.Duration, call func() error) error {
+	return resource.Retry(timeout, func() *resource.RetryError {
+		err := call()
+		if isConnectRebalancing(err) {
+			return resource.RetryableError(err)
+		}
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		return nil
+	})
+}
+
+func resourceKafkaConnectConnectorCreate(d *schema.ResourceData, meta interface{}) error {
+	client, err := kafkaConnect(meta)
+	if err != nil {
+		return err
+	}
+	name := d.Get("name").(string)
+
+	if _, err := client.connectorConfig(name); err == nil {
+		return fmt.Errorf("Connector '%s' already exists; import it instead", name)
+	} else if !isRestAPINotFound(err) {
+		return err
+	}
+
+	d.SetId(name)
+	return applyKafkaConnectConnector(d, client, d.Timeout(schema.TimeoutCreate), meta)
+}
+
+func resourceKafkaConnectConnectorUpdate(d *schema.ResourceData, meta interface{}) error {
+	client, err := kafkaConnect(meta)
+	if err != nil {
+		return err
+	}
+	return applyKafkaConnectConnector(d, client, d.Timeout(schema.TimeoutUpdate), meta)
+}
+
+func applyKafkaConnectConnector(d *schema.ResourceData, client *KafkaConnectClient, timeout time.Duration, meta interface{}) error {
+	name := d.Id()
+
+	if d.IsNewResource() || d.HasChange("config") || d.HasChange("sensitive_config") {
+		config := connectorConfig(d)
+		log.Printf("[INFO] Setting config of connector '%s', keys %v", name, sortedKeys(config))
+		if err := retryConnect(timeout, func() error { return client.putConnectorConfig(name, config) }); err != nil {
+			return err
+		}
+	}
+
+	if d.IsNewResource() || d.HasChange("paused") {
+		call := client.resumeConnector
+		if d.Get("paused").(bool) {
+			call = client.pauseConnector
+		}
+		if err := retryConnect(timeout, func() error { return call(name) }); err != nil {
+			return err
+		}
+		if err := waitForConnectorPause(client, name, d.Get("paused").(bool), timeout); err != nil {
+			return err
+		}
+	}
+
+	return resourceKafkaConnectConnectorRead(d, meta)
+}
+
+// waitForConnectorPause waits for a connector to be paused, or to be out of
+// the paused state, since Connect pauses and resumes connectors
+// asynchronously.
+func waitForConnectorPause(client *KafkaConnectClient, name string, paused bool, timeout time.Duration) error {
+	return resource.Retry(timeout, func() *resource.RetryError {
+		status, err := client.connectorStatus(name)
+		if err != nil && !isRestAPINotFound(err) {
+			return resource.NonRetryableError(err)
+		}
+		if err != nil || (status.Connector.State == connectorPaused) != paused {
+			return resource.RetryableError(fmt.Errorf("Connector '%s' not yet paused=%t", name, paused))
+		}
+		return nil
+	})
+}
+
+// resourceKafkaConnectConnectorRead reads back the running config, so that
+// changes made through the REST API by anything else show up as drift.
+// Keys declared as sensitive stay in sensitive_config.
+func resourceKafkaConnectConnectorRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := kafkaConnect(meta)
+	if err != nil {
+		return err
+	}
+	name := d.Id()
+
+	running, err := client.connectorConfig(name)
+	if isRestAPINotFound(err) {
+		log.Printf("[WARN] Connector '%s' not found, removing from state", name)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	sensitiveKeys := toStringMap(d.Get("sensitive_config"))
+	config := make(map[string]string)
+	sensitive := make(map[string]string)
+	for k, v := range running {
+		if _, ok := sensitiveKeys[k]; ok {
+			sensitive[k] = v
+		} else {
+			config[k] = v
+		}
+	}
+	d.Set("name", name)
+	d.Set("config", config)
+	d.Set("sensitive_config", sensitive)
+
+	status, err := client.connectorStatus(name)
+	if err != nil {
+		return err
+	}
+	d.Set("type", status.Type)
+	d.Set("state", status.Connector.State)
+	d.Set("worker_id", status.Connector.WorkerID)
+	d.Set("paused", status.Connector.State == connectorPaused)
+	d.Set("task", flattenConnectorTasks(status.Tasks))
+
+	for _, t := range status.Tasks {
+		if t.State == connectorFailed {
+			log.Printf("[WARN] Task %d of connector '%s' failed: %s", t.ID, name, t.Trace)
+		}
+	}
+
+	return nil
+}
+
+func resourceKafkaConnectConnectorDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := kafkaConnect(meta)
+	if err != nil {
+		return err
+	}
+
+	err = retryConnect(d.Timeout(schema.TimeoutDelete), func() error { return client.deleteConnector(d.Id()) })
+	if err != nil && !isRestAPINotFound(err) {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func resourceKafkaConnectConnectorCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	config := toStringMap(d.Get("config"))
+	sensitive := toStringMap(d.Get("sensitive_config"))
+
+	for k := range sensitive {
+		if _, ok := config[k]; ok {
+			return fmt.Errorf("%s is set in both config and sensitive_config", k)
+		}
+	}
+	if _, ok := config["name"]; ok {
+		return fmt.Errorf("The connector name is set by the name attribute, not in config")
+	}
+	return nil
+}
+
+// connectorConfig merges the config and sensitive_config of the resource.
+func connectorConfig(d *schema.ResourceData) map[string]string {
+	config := toStringMap(d.Get("config"))
+	for k, v := range toStringMap(d.Get("sensitive_config")) {
+		config[k] = v
+	}
+	return config
+}
+
+func flattenConnectorTasks(tasks []ConnectorTaskStatus) []interface{} {
+	result := make([]interface{}, len(tasks))
+	for i, t := range tasks {
+		result[i] = map[string]interface{}{
+			"id":        t.ID,
+			"state":     t.State,
+			"worker_id": t.WorkerID,
+			"trace":     t.Trace,
+		}
+	}
+	return result
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+// consumerGroupIdleStates are the states in which a group has no member
+// that could commit over a reset.
+var consumerGroupIdleStates = map[string]bool{"Empty": true, "Dead": true}
+
+func resourceKafkaConsumerGroupOffsets() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKafkaConsumerGroupOffsetsCreate,
+		Read:   resourceKafkaConsumerGroupOffsetsRead,
+		Update: resourceKafkaConsumerGroupOffsetsUpdate,
+		Delete: resourceKafkaConsumerGroupOffsetsDelete,
+
+		CustomizeDiff: resourceKafkaConsumerGroupOffsetsCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"group_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "consumer group whose offsets to reset",
+			},
+			"topic": &schema.Schema{
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Description: "topics whose offsets to reset",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"partitions": &schema.Schema{
+							Type:        schema.TypeList,
+							Optional:    true,
+							Description: "partitions to reset, all if empty",
+							Elem:        &schema.Schema{Type: schema.TypeInt},
+						},
+					},
+				},
+			},
+			"strategy": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "earliest, latest, timestamp, offset or shift_by",
+				ValidateFunc: validation.StringInSlice([]string{
+					offsetResetEarliest, offsetResetLatest, offsetResetTimestamp, offsetResetOffset, offsetResetShiftBy,
+				}, false),
+			},
+			"timestamp": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "RFC3339 time whose first offsets to reset to, for the timestamp strategy",
+				ValidateFunc: validation.ValidateRFC3339TimeString,
+			},
+			"offset": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "offset to reset to for the offset strategy, or number of offsets to move by, possibly negative, for shift_by",
+			},
+			"triggers": &schema.Schema{
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "arbitrary values whose change resets the offsets again",
+			},
+			"offsets": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "offsets the group has committed on the topics",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"topic": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"partition": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"offset": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceKafkaConsumerGroupOffsetsCreate(d *schema.ResourceData, meta interface{}) error {
+	if err := resetKafkaConsumerGroupOffsets(d, meta); err != nil {
+		return err
+	}
+
+	d.SetId(d.Get("group_id").(string))
+	return resourceKafkaConsumerGroupOffsetsRead(d, meta)
+}
+
+func resourceKafkaConsumerGroupOffsetsUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := resetKafkaConsumerGroupOffsets(d, meta); err != nil {
+		return err
+	}
+	return resourceKafkaConsumerGroupOffsetsRead(d, meta)
+}
+
+// resetKafkaConsumerGroupOffsets checks the group is idle before resetting
+// its offsets, since members would keep consuming from, and committing,
+// their current positions.
+func resetKafkaConsumerGroupOffsets(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+	group := d.Get("group_id").(string)
+
+	state, members, err := client.consumerGroupState(group)
+	if err != nil {
+		return err
+	}
+	if members > 0 || !consumerGroupIdleStates[state] {
+		return fmt.Errorf("Consumer group '%s' is %s with %d active member(s); stop its consumers before resetting offsets", group, state, members)
+	}
+
+	reset := OffsetReset{
+		Strategy: d.Get("strategy").(string),
+		Offset:   int64(d.Get("offset").(int)),
+	}
+	if reset.Strategy == offsetResetTimestamp {
+		if reset.Timestamp, err = time.Parse(time.RFC3339, d.Get("timestamp").(string)); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("[INFO] Resetting offsets of consumer group '%s' with strategy %s", group, reset.Strategy)
+	return client.resetConsumerGroupOffsets(group, offsetResetTopics(d), reset)
+}
+
+func resourceKafkaConsumerGroupOffsetsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+
+	offsets, err := client.consumerGroupOffsets(d.Id())
+	if err != nil {
+		return err
+	}
+
+	topics := make(map[string]bool)
+	for _, raw := range d.Get("topic").([]interface{}) {
+		topics[raw.(map[string]interface{})["name"].(string)] = true
+	}
+
+	var result []interface{}
+	for _, o := range offsets {
+		if topics[o.Topic] {
+			result = append(result, map[string]interface{}{
+				"topic":     o.Topic,
+				"partition": o.Partition,
+				"offset":    int(o.Offset),
+			})
+		}
+	}
+	d.Set("offsets", result)
+
+	return nil
+}
+
+// resourceKafkaConsumerGroupOffsetsDelete leaves the committed offsets as
+// they are: there is nothing to put back.
+func resourceKafkaConsumerGroupOffsetsDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[INFO] Leaving offsets of consumer group '%s' in place", d.Id())
+	d.SetId("")
+	return nil
+}
+
+func resourceKafkaConsumerGroupOffsetsCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	switch d.Get("strategy").(string) {
+	case offsetResetTimestamp:
+		if d.Get("timestamp").(string) == "" {
+			return fmt.Errorf("The timestamp strategy needs a timestamp")
+		}
+	case offsetResetOffset:
+		if d.Get("offset").(int) < 0 {
+			return fmt.Errorf("The offset strategy needs an offset of at least 0")
+		}
+	}
+	return nil
+}
+
+func offsetResetTopics(d *schema.ResourceData) []string {
+	var topics []string
+	for _, raw := range d.Get("topic").([]interface{}) {
+		t := raw.(map[string]interface{})
+		var partitions []int
+		for _, p := range t["partitions"].([]interface{}) {
+			partitions = append(partitions, p.(int))
+		}
+		topics = append(topics, topicPartitionsArg(t["name"].(string), partitions))
+	}
+	return topics
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceKafkaPartitionReassignment() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKafkaPartitionReassignmentCreate,
+		Read:   resourceKafkaPartitionReassignmentRead,
+		Update: resourceKafkaPartitionReassignmentUpdate,
+		Delete: resourceKafkaPartitionReassignmentDelete,
+
+		CustomizeDiff: resourceKafkaPartitionReassignmentCustomizeDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Update: schema.DefaultTimeout(30 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"partition": &schema.Schema{
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Description: "target replicas of each partition to reassign",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"topic": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"partition": &schema.Schema{
+							Type:     schema.TypeInt,
+							Required: true,
+						},
+						"replicas": &schema.Schema{
+							Type:        schema.TypeList,
+							Required:    true,
+							Description: "broker ids, the first being the preferred leader",
+							Elem:        &schema.Schema{Type: schema.TypeInt},
+						},
+					},
+				},
+			},
+			"throttle": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "bytes per second the reassignment may replicate at, -1 for no throttle",
+				Default:      -1,
+				ValidateFunc: validateReassignmentThrottle,
+			},
+			"status": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "complete, in_progress or failed",
+			},
+			"progress": &schema.Schema{
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "status of each partition, keyed as topic-partition",
+			},
+		},
+	}
+}
+
+func resourceKafkaPartitionReassignmentCreate(d *schema.ResourceData, meta interface{}) error {
+	partitions := buildPartitionReassignments(d)
+	d.SetId(partitionReassignmentID(partitions))
+
+	return reassignKafkaPartitions(d, meta, partitions, d.Timeout(schema.TimeoutCreate))
+}
+
+func resourceKafkaPartitionReassignmentUpdate(d *schema.ResourceData, meta interface{}) error {
+	return reassignKafkaPartitions(d, meta, buildPartitionReassignments(d), d.Timeout(schema.TimeoutUpdate))
+}
+
+// reassignKafkaPartitions submits the reassignment and verifies it until it
+// completes, which also removes its throttle. A reassignment still running
+// at the timeout goes on in the cluster: it is saved as in_progress rather
+// than failed, which would taint the resource, and later refreshes verify
+// it again.
+func reassignKafkaPartitions(d *schema.ResourceData, meta interface{}, partitions []PartitionReassignment, timeout time.Duration) error {
+	client := meta.(*KafkaManagingClient)
+
+	log.Printf("[INFO] Reassigning partitions %v", partitions)
+	if err := client.executeReassignment(partitions, d.Get("throttle").(int)); err != nil {
+		return err
+	}
+
+	var progress string
+	var status map[string]string
+	err := resource.Retry(timeout, func() *resource.RetryError {
+		var err error
+		progress = ""
+		if status, err = client.verifyReassignment(partitions); err != nil {
+			return resource.NonRetryableError(err)
+		}
+		progress = reassignmentProgress(partitions, status)
+
+		switch progress {
+		case reassignmentFailed:
+			return resource.NonRetryableError(fmt.Errorf("Partition reassignment failed: %v", status))
+		case reassignmentInProgress:
+			return resource.RetryableError(fmt.Errorf("Partition reassignment still in progress: %v", status))
+		}
+		return nil
+	})
+	if progress != "" {
+		d.Set("status", progress)
+		d.Set("progress", status)
+	}
+	if err != nil && progress != reassignmentInProgress {
+		return err
+	}
+	if err != nil {
+		log.Printf("[WARN] Partition reassignment %s still in progress after %s, saving it as in_progress", d.Id(), timeout)
+	}
+
+	return resourceKafkaPartitionReassignmentRead(d, meta)
+}
+
+// resourceKafkaPartitionReassignmentRead verifies a reassignment left in
+// progress, and once it is done reads back the replicas of every partition
+// so that replicas moved since show as drift. The replicas of a
+// reassignment still in progress are not read back, as they are mid-move.
+func resourceKafkaPartitionReassignmentRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+	partitions := buildPartitionReassignments(d)
+
+	if d.Get("status").(string) == reassignmentInProgress {
+		status, err := client.verifyReassignment(partitions)
+		if err != nil {
+			return err
+		}
+		progress := reassignmentProgress(partitions, status)
+		d.Set("status", progress)
+		d.Set("progress", status)
+		if progress == reassignmentInProgress {
+			return nil
+		}
+	}
+
+	topics := make(map[string]*KafkaTopicInfo)
+	for i, p := range partitions {
+		info, ok := topics[p.Topic]
+		if !ok {
+			var err error
+			if info, err = client.describeTopic(p.Topic); err != nil {
+				return err
+			}
+			topics[p.Topic] = info
+		}
+
+		partitions[i].Replicas = nil
+		if info.exists() {
+			for _, actual := range info.Partitions {
+				if actual.ID == p.Partition {
+					partitions[i].Replicas = actual.Replicas
+				}
+			}
+		}
+	}
+
+	d.Set("partition", flattenPartitionReassignments(partitions))
+	return nil
+}
+
+// resourceKafkaPartitionReassignmentDelete leaves the partitions where they
+// are: there is no previous assignment to go back to.
+func resourceKafkaPartitionReassignmentDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[INFO] Leaving reassigned partitions %s in place", d.Id())
+	d.SetId("")
+	return nil
+}
+
+func resourceKafkaPartitionReassignmentCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	return checkReassignment(expandPartitionReassignments(d.Get("partition").([]interface{})))
+}
+
+// validateReassignmentThrottle accepts -1 for no throttle or a positive
+// rate: a throttle of 0 would stop the reassignment from moving any data.
+func validateReassignmentThrottle(v interface{}, k string) ([]string, []error) {
+	if throttle := v.(int); throttle != -1 && throttle <= 0 {
+		return nil, []error{fmt.Errorf("%s must be -1 for no throttle or a positive number of bytes per second, got %d", k, throttle)}
+	}
+	return nil, nil
+}
+
+func buildPartitionReassignments(d *schema.ResourceData) []PartitionReassignment {
+	return expandPartitionReassignments(d.Get("partition").([]interface{}))
+}
+
+func expandPartitionReassignments(raw []interface{}) []PartitionReassignment {
+	var partitions []PartitionReassignment
+	for _, r := range raw {
+		m := r.(map[string]interface{})
+		p := PartitionReassignment{
+			Topic:     m["topic"].(string),
+			Partition: m["partition"].(int),
+			Replicas:  []int{},
+		}
+		for _, b := range m["replicas"].([]interface{}) {
+			p.Replicas = append(p.Replicas, b.(int))
+		}
+		partitions = append(partitions, p)
+	}
+	return partitions
+}
+
+func flattenPartitionReassignments(partitions []PartitionReassignment) []interface{} {
+	result := make([]interface{}, len(partitions))
+	for i, p := range partitions {
+		result[i] = map[string]interface{}{
+			"topic":     p.Topic,
+			"partition": p.Partition,
+			"replicas":  p.Replicas,
+		}
+	}
+	return result
+}
+
+// partitionReassignmentID names a reassignment after the topics it moves.
+func partitionReassignmentID(partitions []PartitionReassignment) string {
+	seen := make(map[string]bool)
+	var topics []string
+	for _, p := range partitions {
+		if !seen[p.Topic] {
+			seen[p.Topic] = true
+			topics = append(topics, p.Topic)
+		}
+	}
+	sort.Strings(topics)
+	return strings.Join(topics, ",")
+}
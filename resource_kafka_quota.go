@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceKafkaQuota() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKafkaQuotaCreate,
+		Read:   resourceKafkaQuotaRead,
+		Update: resourceKafkaQuotaUpdate,
+		Delete: resourceKafkaQuotaDelete,
+
+		CustomizeDiff: resourceKafkaQuotaCustomizeDiff,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceKafkaQuotaImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"user": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "user principal the quota applies to, " + entityDefault + " for the default of all users",
+				Default:     "",
+			},
+			"client_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "client-id the quota applies to, " + entityDefault + " for the default of all client-ids",
+				Default:     "",
+			},
+			quotaProducerByteRate: &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "bytes per second each broker accepts from producers, -1 for no quota",
+				Default:     -1,
+			},
+			quotaConsumerByteRate: &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "bytes per second each broker serves to consumers, -1 for no quota",
+				Default:     -1,
+			},
+			quotaRequestPercentage: &schema.Schema{
+				Type:        schema.TypeFloat,
+				Optional:    true,
+				Description: "percentage of a request handler or network thread each broker grants, -1 for no quota",
+				Default:     -1.0,
+			},
+		},
+	}
+}
+
+func resourceKafkaQuotaCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+	user := d.Get("user").(string)
+	clientID := d.Get("client_id").(string)
+	entity := quotaEntity(user, clientID)
+
+	actual, err := client.describeEntityConfig(entity)
+	if err != nil {
+		return err
+	}
+	if existing := quotaConfig(actual); len(existing) > 0 {
+		return fmt.Errorf("Quotas already set for %s: %v; import them instead", entity, existing)
+	}
+
+	mods := diffConfig(desiredQuotaConfig(d), actual, isQuotaKey)
+	if err := client.alterEntityConfig(entity, mods); err != nil {
+		return err
+	}
+
+	d.SetId(quotaID(user, clientID))
+	return resourceKafkaQuotaRead(d, meta)
+}
+
+func resourceKafkaQuotaUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+	user, clientID, err := parseQuotaID(d.Id())
+	if err != nil {
+		return err
+	}
+	entity := quotaEntity(user, clientID)
+
+	actual, err := client.describeEntityConfig(entity)
+	if err != nil {
+		return err
+	}
+
+	mods := diffConfig(desiredQuotaConfig(d), actual, isQuotaKey)
+	if !mods.empty() {
+		if err := client.alterEntityConfig(entity, mods); err != nil {
+			return err
+		}
+	}
+
+	return resourceKafkaQuotaRead(d, meta)
+}
+
+func resourceKafkaQuotaRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+	user, clientID, err := parseQuotaID(d.Id())
+	if err != nil {
+		return err
+	}
+	entity := quotaEntity(user, clientID)
+
+	actual, err := client.describeEntityConfig(entity)
+	if err != nil {
+		return err
+	}
+
+	quotas := quotaConfig(actual)
+	if len(quotas) == 0 {
+		log.Printf("[WARN] No quota set for %s, removing from state", entity)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("user", user)
+	d.Set("client_id", clientID)
+	d.Set(quotaProducerByteRate, getOrDefaultInt(quotas, quotaProducerByteRate, -1))
+	d.Set(quotaConsumerByteRate, getOrDefaultInt(quotas, quotaConsumerByteRate, -1))
+
+	percentage := -1.0
+	if v, ok := quotas[quotaRequestPercentage]; ok {
+		if percentage, err = strconv.ParseFloat(v, 64); err != nil {
+			return fmt.Errorf("Unable to parse %s of %s: %s", quotaRequestPercentage, entity, err)
+		}
+	}
+	d.Set(quotaRequestPercentage, percentage)
+
+	return nil
+}
+
+func resourceKafkaQuotaDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+	user, clientID, err := parseQuotaID(d.Id())
+	if err != nil {
+		return err
+	}
+	entity := quotaEntity(user, clientID)
+
+	actual, err := client.describeEntityConfig(entity)
+	if err != nil {
+		return err
+	}
+
+	mods := diffConfig(map[string]string{}, actual, isQuotaKey)
+	if !mods.empty() {
+		if err := client.alterEntityConfig(entity, mods); err != nil {
+			return err
+		}
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func resourceKafkaQuotaImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if _, _, err := parseQuotaID(d.Id()); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceKafkaQuotaCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Get("user").(string) == "" && d.Get("client_id").(string) == "" {
+		return fmt.Errorf("A kafka_quota needs a user, a client_id or both")
+	}
+
+	return checkQuotaRates(d.Get(quotaProducerByteRate).(int), d.Get(quotaConsumerByteRate).(int), d.Get(quotaRequestPercentage).(float64))
+}
+
+// desiredQuotaConfig builds the quota keys of the entity from the set
+// attributes; -1 leaves a quota out.
+func desiredQuotaConfig(d *schema.ResourceData) map[string]string {
+	conf := make(map[string]string)
+	for _, k := range []string{quotaProducerByteRate, quotaConsumerByteRate} {
+		if v := d.Get(k).(int); v >= 0 {
+			conf[k] = strconv.Itoa(v)
+		}
+	}
+	if v := d.Get(quotaRequestPercentage).(float64); v >= 0 {
+		conf[quotaRequestPercentage] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return conf
+}
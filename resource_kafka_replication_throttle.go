@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceKafkaReplicationThrottle() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKafkaReplicationThrottleCreate,
+		Read:   resourceKafkaReplicationThrottleRead,
+		Update: resourceKafkaReplicationThrottleUpdate,
+		Delete: resourceKafkaReplicationThrottleDelete,
+
+		CustomizeDiff: resourceKafkaReplicationThrottleCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"broker_ids": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "brokers the rates apply to",
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+			},
+			"leader_rate": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "bytes per second each broker may send to throttled followers, -1 for no throttle",
+				Default:     -1,
+			},
+			"follower_rate": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "bytes per second each broker may fetch for throttled replicas, -1 for no throttle",
+				Default:     -1,
+			},
+			"topic": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "topics whose replicas are throttled",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"leader_replicas": &schema.Schema{
+							Type:        schema.TypeList,
+							Optional:    true,
+							Description: "partition:broker leader replicas to throttle, or * for all",
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validateThrottledReplica,
+							},
+						},
+						"follower_replicas": &schema.Schema{
+							Type:        schema.TypeList,
+							Optional:    true,
+							Description: "partition:broker follower replicas to throttle, or * for all",
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validateThrottledReplica,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceKafkaReplicationThrottleCreate(d *schema.ResourceData, meta interface{}) error {
+	if err := applyReplicationThrottle(d, meta); err != nil {
+		return err
+	}
+
+	d.SetId(replicationThrottleID(d))
+	return resourceKafkaReplicationThrottleRead(d, meta)
+}
+
+func resourceKafkaReplicationThrottleUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := applyReplicationThrottle(d, meta); err != nil {
+		return err
+	}
+
+	d.SetId(replicationThrottleID(d))
+	return resourceKafkaReplicationThrottleRead(d, meta)
+}
+
+// applyReplicationThrottle converges the throttle configs of every broker
+// and topic, clearing them from the ones no longer listed. The resource
+// owns all throttle keys of the entities it lists.
+func applyReplicationThrottle(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+
+	o, n := d.GetChange("broker_ids")
+	rates := throttleRateConfig(d.Get("leader_rate").(int), d.Get("follower_rate").(int))
+	desired := make(map[string]map[string]string)
+	for _, b := range throttledBrokers(o) {
+		desired[b] = map[string]string{}
+	}
+	for _, b := range throttledBrokers(n) {
+		desired[b] = rates
+	}
+	for _, b := range sortedEntityKeys(desired) {
+		if err := applyThrottleConfig(client, brokerEntity(b), desired[b]); err != nil {
+			return err
+		}
+	}
+
+	o, n = d.GetChange("topic")
+	desired = make(map[string]map[string]string)
+	for name := range throttledTopics(o) {
+		desired[name] = map[string]string{}
+	}
+	for name, conf := range throttledTopics(n) {
+		desired[name] = conf
+	}
+	for _, name := range sortedEntityKeys(desired) {
+		if err := applyThrottleConfig(client, topicEntity(name), desired[name]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func applyThrottleConfig(client *KafkaManagingClient, entity configEntity, desired map[string]string) error {
+	actual, err := client.describeEntityConfig(entity)
+	if err != nil {
+		return err
+	}
+
+	mods := diffConfig(desired, actual, isThrottleConfigKey)
+	if mods.empty() {
+		return nil
+	}
+	return client.alterEntityConfig(entity, mods)
+}
+
+// resourceKafkaReplicationThrottleRead reads back the throttles. Every
+// broker is compared with the declared rates, and the rates of the first
+// broker that differs are reported.
+func resourceKafkaReplicationThrottleRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+
+	leaderRate := d.Get("leader_rate").(int)
+	followerRate := d.Get("follower_rate").(int)
+	for _, b := range throttledBrokers(d.Get("broker_ids")) {
+		actual, err := client.describeEntityConfig(brokerEntity(b))
+		if err != nil {
+			return err
+		}
+		leader := int(getOrDefaultInt(actual, leaderThrottledRate, -1))
+		follower := int(getOrDefaultInt(actual, followerThrottledRate, -1))
+		if leader != leaderRate || follower != followerRate {
+			log.Printf("[DEBUG] Kafka broker %s throttles replication at %d/%d rather than %d/%d", b, leader, follower, leaderRate, followerRate)
+			d.Set("leader_rate", leader)
+			d.Set("follower_rate", follower)
+			break
+		}
+	}
+
+	topics := d.Get("topic").([]interface{})
+	result := make([]interface{}, len(topics))
+	for i, raw := range topics {
+		name := raw.(map[string]interface{})["name"].(string)
+		actual, err := client.describeEntityConfig(topicEntity(name))
+		if err != nil {
+			return err
+		}
+		result[i] = map[string]interface{}{
+			"name":              name,
+			"leader_replicas":   splitThrottledReplicas(actual[leaderThrottledReplicas]),
+			"follower_replicas": splitThrottledReplicas(actual[followerThrottledReplicas]),
+		}
+	}
+	d.Set("topic", result)
+
+	return nil
+}
+
+func resourceKafkaReplicationThrottleDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+
+	for _, b := range throttledBrokers(d.Get("broker_ids")) {
+		if err := applyThrottleConfig(client, brokerEntity(b), map[string]string{}); err != nil {
+			return err
+		}
+	}
+	for name := range throttledTopics(d.Get("topic")) {
+		if err := applyThrottleConfig(client, topicEntity(name), map[string]string{}); err != nil {
+			return err
+		}
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func resourceKafkaReplicationThrottleCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	brokers := d.Get("broker_ids").([]interface{})
+	topics := d.Get("topic").([]interface{})
+	if len(brokers) == 0 && len(topics) == 0 {
+		return fmt.Errorf("A kafka_replication_throttle needs broker_ids, a topic or both")
+	}
+
+	for _, raw := range topics {
+		t := raw.(map[string]interface{})
+		for _, attr := range []string{"leader_replicas", "follower_replicas"} {
+			if err := checkThrottledReplicas(toStrings(t[attr])); err != nil {
+				return fmt.Errorf("topic %s %s: %s", t["name"], attr, err)
+			}
+		}
+	}
+	return nil
+}
+
+func throttledBrokers(v interface{}) []string {
+	var brokers []string
+	for _, b := range v.([]interface{}) {
+		brokers = append(brokers, strconv.Itoa(b.(int)))
+	}
+	return brokers
+}
+
+func throttledTopics(v interface{}) map[string]map[string]string {
+	topics := make(map[string]map[string]string)
+	for _, raw := range v.([]interface{}) {
+		t := raw.(map[string]interface{})
+		topics[t["name"].(string)] = throttleReplicasConfig(toStrings(t["leader_replicas"]), toStrings(t["follower_replicas"]))
+	}
+	return topics
+}
+
+func splitThrottledReplicas(v string) []string {
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
+
+func sortedEntityKeys(m map[string]map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// replicationThrottleID names a throttle after the brokers and topics it
+// applies to.
+func replicationThrottleID(d *schema.ResourceData) string {
+	var topics []string
+	for name := range throttledTopics(d.Get("topic")) {
+		topics = append(topics, name)
+	}
+	sort.Strings(topics)
+	return strings.Join(throttledBrokers(d.Get("broker_ids")), ",") + "|" + strings.Join(topics, ",")
+}
+
+func toStrings(v interface{}) []string {
+	var result []string
+	for _, s := range v.([]interface{}) {
+		result = append(result, s.(string))
+	}
+	return result
+}
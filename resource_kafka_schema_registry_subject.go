@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func resourceKafkaSchemaRegistrySubject() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKafkaSchemaRegistrySubjectCreate,
+		Read:   resourceKafkaSchemaRegistrySubjectRead,
+		Update: resourceKafkaSchemaRegistrySubjectUpdate,
+		Delete: resourceKafkaSchemaRegistrySubjectDelete,
+
+		CustomizeDiff: resourceKafkaSchemaRegistrySubjectCustomizeDiff,
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"subject": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      true,
+				Description:   "subject to register the schema under",
+				ConflictsWith: []string{"topic"},
+			},
+			"topic": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				Description:   "topic whose key or value subject to use, named as by TopicNameStrategy",
+				ConflictsWith: []string{"subject"},
+			},
+			"is_key": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "use the topic's key subject rather than its value one",
+				Default:     false,
+			},
+			"schema": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "schema definition; changing it registers a new version",
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return sameSchema(d.Get("schema_type").(string), old, new)
+				},
+			},
+			"schema_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "AVRO, JSON or PROTOBUF",
+				Default:      schemaTypeAvro,
+				ValidateFunc: validation.StringInSlice([]string{schemaTypeAvro, schemaTypeJSON, schemaTypeProtobuf}, false),
+			},
+			"compatibility_level": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "compatibility level of the subject, the registry's global one if empty",
+				Default:      "",
+				ValidateFunc: validation.StringInSlice(append([]string{""}, compatibilityLevels...), false),
+			},
+			"hard_delete": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "delete the subject permanently on destroy rather than soft-deleting it",
+				Default:     false,
+			},
+			"schema_id": &schema.Schema{
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "registry-wide id of the schema",
+			},
+			"version": &schema.Schema{
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "version of the schema under the subject",
+			},
+		},
+	}
+}
+
+func schemaRegistry(meta interface{}) (*SchemaRegistryClient, error) {
+	client := meta.(*KafkaManagingClient).SchemaRegistry
+	if client == nil {
+		return nil, fmt.Errorf("Managing schema registry subjects needs the provider's schema_registry_url")
+	}
+	return client, nil
+}
+
+func resourceKafkaSchemaRegistrySubjectCreate(d *schema.ResourceData, meta interface{}) error {
+	subject := d.Get("subject").(string)
+	if topic := d.Get("topic").(string); topic != "" {
+		subject = topicNameStrategySubject(topic, d.Get("is_key").(bool))
+	}
+
+	d.SetId(subject)
+	return resourceKafkaSchemaRegistrySubjectUpdate(d, meta)
+}
+
+// resourceKafkaSchemaRegistrySubjectUpdate sets the compatibility level
+// before registering the schema, so that the schema is checked against the
+// level it is meant to meet.
+func resourceKafkaSchemaRegistrySubjectUpdate(d *schema.ResourceData, meta interface{}) error {
+	client, err := schemaRegistry(meta)
+	if err != nil {
+		return err
+	}
+	subject := d.Id()
+
+	if d.IsNewResource() || d.HasChange("compatibility_level") {
+		if level := d.Get("compatibility_level").(string); level != "" {
+			err = client.setCompatibility(subject, level)
+		} else if !d.IsNewResource() {
+			err = client.resetCompatibility(subject)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.IsNewResource() || d.HasChange("schema") || d.HasChange("schema_type") {
+		log.Printf("[INFO] Registering schema under subject '%s'", subject)
+		if _, err := client.registerSchema(subject, d.Get("schema_type").(string), d.Get("schema").(string)); err != nil {
+			return err
+		}
+	}
+
+	return resourceKafkaSchemaRegistrySubjectRead(d, meta)
+}
+
+// resourceKafkaSchemaRegistrySubjectRead compares the latest version of the
+// subject with the declared schema, so that a version registered by someone
+// else shows up as drift.
+func resourceKafkaSchemaRegistrySubjectRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := schemaRegistry(meta)
+	if err != nil {
+		return err
+	}
+	subject := d.Id()
+
+	latest, err := client.latestSchema(subject)
+	if isRestAPINotFound(err) {
+		log.Printf("[WARN] Schema registry subject '%s' not found, removing from state", subject)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	d.Set("subject", subject)
+	d.Set("schema_type", latest.SchemaType)
+	d.Set("schema_id", latest.ID)
+	d.Set("version", latest.Version)
+	if !sameSchema(latest.SchemaType, latest.Schema, d.Get("schema").(string)) {
+		d.Set("schema", latest.Schema)
+	}
+
+	level, err := client.compatibility(subject)
+	if err != nil {
+		return err
+	}
+	d.Set("compatibility_level", level)
+
+	return nil
+}
+
+func resourceKafkaSchemaRegistrySubjectDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := schemaRegistry(meta)
+	if err != nil {
+		return err
+	}
+
+	if err := client.deleteSubject(d.Id(), d.Get("hard_delete").(bool)); err != nil && !isRestAPINotFound(err) {
+		return err
+	}
+	if err := client.resetCompatibility(d.Id()); err != nil {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// resourceKafkaSchemaRegistrySubjectCustomizeDiff plans the subject named
+// after the topic, so that the plan shows it.
+func resourceKafkaSchemaRegistrySubjectCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("topic") {
+		return d.SetNewComputed("subject")
+	}
+
+	topic := d.Get("topic").(string)
+	if topic == "" {
+		if d.Get("subject").(string) == "" && d.NewValueKnown("subject") {
+			return fmt.Errorf("A kafka_schema_registry_subject needs a subject or a topic")
+		}
+		return nil
+	}
+
+	return d.SetNew("subject", topicNameStrategySubject(topic, d.Get("is_key").(bool)))
+}
@@ -3,8 +3,25 @@ package main
 import (
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+const (
+	unmanagedConfigIgnore = "ignore"
+	unmanagedConfigWarn   = "warn"
+	unmanagedConfigRemove = "remove"
+)
+
+const (
+	ifExistsFail            = "fail"
+	ifExistsAdopt           = "adopt"
+	ifExistsAdoptIfMatching = "adopt_if_matching"
 )
 
 func resourceKafkaTopic() *schema.Resource {
@@ -14,12 +31,26 @@ func resourceKafkaTopic() *schema.Resource {
 		Update: resourceKafkaTopicUpdate,
 		Delete: resourceKafkaTopicDelete,
 
+		CustomizeDiff: resourceKafkaTopicCustomizeDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "topic name, after the provider's topic_name_prefix",
+				ValidateFunc: validateTopicName,
+			},
+			"full_name": &schema.Schema{
 				Type:        schema.TypeString,
-				Required:    true,
+				Computed:    true,
 				ForceNew:    true,
-				Description: "topic name",
+				Description: "name of the topic in Kafka, including the provider's topic_name_prefix",
 			},
 			"partitions": &schema.Schema{
 				Type:        schema.TypeInt,
@@ -28,21 +59,24 @@ func resourceKafkaTopic() *schema.Resource {
 			},
 			"replication_factor": &schema.Schema{
 				Type:        schema.TypeInt,
-				Required:    true,
+				Optional:    true,
+				Computed:    true,
 				ForceNew:    true,
-				Description: "replication factor",
+				Description: "replication factor, taken from the provider's default_topic_config if not set",
 			},
 			"retention_bytes": &schema.Schema{
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Description: "log.retention.bytes",
-				Default:     -1,
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "log.retention.bytes, -1 for no size limit",
+				Default:      topicConfigUnset,
+				ValidateFunc: validation.IntAtLeast(-1),
 			},
 			"retention_ms": &schema.Schema{
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Description: "log.retention.ms",
-				Default:     -1,
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "log.retention.ms, -1 for no time limit",
+				Default:      topicConfigUnset,
+				ValidateFunc: validation.IntAtLeast(-1),
 			},
 			"cleanup_policy": &schema.Schema{
 				Type:        schema.TypeString,
@@ -51,16 +85,204 @@ func resourceKafkaTopic() *schema.Resource {
 				Default:     "",
 			},
 			"segment_bytes": &schema.Schema{
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Description: "segment.bytes",
-				Default:     -1,
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "segment.bytes",
+				Default:      topicConfigUnset,
+				ValidateFunc: validation.IntAtLeast(1),
 			},
 			"segment_ms": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "segment.ms",
+				Default:      topicConfigUnset,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"retention": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "retention.ms as a duration, e.g. 7d",
+				ConflictsWith: []string{"retention_ms"},
+				ValidateFunc:  validateUnitAttr(parseDuration),
+				StateFunc:     canonicalUnitAttr(parseDuration, formatDuration),
+			},
+			"retention_size": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "retention.bytes as a size, e.g. 10GiB",
+				ConflictsWith: []string{"retention_bytes"},
+				ValidateFunc:  validateUnitAttr(parseSize),
+				StateFunc:     canonicalUnitAttr(parseSize, formatSize),
+			},
+			"segment": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "segment.ms as a duration, e.g. 1d",
+				ConflictsWith: []string{"segment_ms"},
+				ValidateFunc:  validateUnitAttr(parseDuration),
+				StateFunc:     canonicalUnitAttr(parseDuration, formatDuration),
+			},
+			"segment_size": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "segment.bytes as a size, e.g. 1GiB",
+				ConflictsWith: []string{"segment_bytes"},
+				ValidateFunc:  validateUnitAttr(parseSize),
+				StateFunc:     canonicalUnitAttr(parseSize, formatSize),
+			},
+			"config": &schema.Schema{
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "further topic-level config overrides, keyed by Kafka config name",
+			},
+			"ignore_defaults": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "settings of the provider's default_topic_config not to apply: replication_factor or Kafka config names",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+			},
+			"merged_config": &schema.Schema{
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "config overrides applied to the topic: the resource's own laid over the provider's default_topic_config",
+			},
+			"if_exists": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "what to do on create when the topic already exists: fail, adopt or adopt_if_matching",
+				Default:     ifExistsFail,
+				ValidateFunc: validation.StringInSlice([]string{
+					ifExistsFail,
+					ifExistsAdopt,
+					ifExistsAdoptIfMatching,
+				}, false),
+			},
+			"expires_at": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				Description:   "RFC3339 time after which the topic is expired and gets replaced",
+				ConflictsWith: []string{"ttl"},
+				ValidateFunc:  validation.ValidateRFC3339TimeString,
+			},
+			"ttl": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "how long after creation the topic expires, e.g. 3d",
+				ConflictsWith: []string{"expires_at"},
+				ValidateFunc:  validateUnitAttr(parseDuration),
+				StateFunc:     canonicalUnitAttr(parseDuration, formatDuration),
+			},
+			"created_at": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "RFC3339 time the topic was created or adopted, from which ttl counts",
+			},
+			"expired": &schema.Schema{
+				Type:        schema.TypeBool,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "true once expires_at has passed",
+			},
+			"retain_on_destroy": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "on destroy, only remove the topic from state and leave it in Kafka",
+				Default:     false,
+			},
+			"unmanaged_config_policy": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "what to do with config overrides not managed by this resource: ignore, warn or remove",
+				Default:     unmanagedConfigIgnore,
+				ValidateFunc: validation.StringInSlice([]string{
+					unmanagedConfigIgnore,
+					unmanagedConfigWarn,
+					unmanagedConfigRemove,
+				}, false),
+			},
+			"all_config": &schema.Schema{
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "all topic-level config overrides, including unmanaged ones",
+			},
+			"partition": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "placement and state of each partition",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"leader": &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "broker leading the partition, -1 if none",
+						},
+						"replicas": &schema.Schema{
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeInt},
+						},
+						"isr": &schema.Schema{
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "in-sync replicas",
+							Elem:        &schema.Schema{Type: schema.TypeInt},
+						},
+						"offline": &schema.Schema{
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "true if the partition has no leader",
+						},
+					},
+				},
+			},
+			"under_replicated_partitions": &schema.Schema{
 				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "number of partitions with fewer in-sync replicas than replicas",
+			},
+			"offline_partitions": &schema.Schema{
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "number of partitions without a leader",
+			},
+			"isr_below_min": &schema.Schema{
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "number of partitions with fewer in-sync replicas than min.insync.replicas",
+			},
+			"require_healthy": &schema.Schema{
+				Type:        schema.TypeBool,
 				Optional:    true,
-				Description: "segment.ms",
-				Default:     -1,
+				Description: "fail create and update unless the topic becomes healthy within the timeout",
+				Default:     false,
+			},
+			"elect_preferred_leaders": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "after create and update, move leadership back to each partition's preferred replica and wait for it",
+				Default:     false,
+			},
+			"unclean_leader_election": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "before electing preferred leaders, elect out-of-sync replicas for partitions without a leader, which can lose data",
+				Default:     false,
+			},
+			"effective_config": &schema.Schema{
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "every config value in effect for the topic, including broker defaults (needs bootstrap_servers)",
+			},
+			"config_sources": &schema.Schema{
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "where each effective config value comes from, e.g. DYNAMIC_TOPIC_CONFIG or DEFAULT_CONFIG",
 			},
 		},
 	}
@@ -69,13 +291,35 @@ func resourceKafkaTopic() *schema.Resource {
 func resourceKafkaTopicCreate(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*KafkaManagingClient)
 
-	topicName := d.Get("name").(string)
+	topicName := client.TopicNamePolicy.fullName(d.Get("name").(string))
+
+	// checked again here for names only known at apply time
+	if err := client.TopicNamePolicy.check(topicName); err != nil {
+		return fmt.Errorf("name: %s", err)
+	}
+	if err := checkTopicCollision(client, topicName); err != nil {
+		return err
+	}
 
 	log.Printf("[DEBUG] Kafka to create topic '%s'", topicName)
 
-	conf := buildKafkaConfig(d)
+	conf := buildKafkaConfig(d, client.TopicDefaults)
+	if conf.ReplicationFactor < 1 {
+		return fmt.Errorf("replication_factor must be set on kafka_topic '%s' or in the provider's default_topic_config", topicName)
+	}
+
+	if ifExists := d.Get("if_exists").(string); ifExists != ifExistsFail {
+		existing, err := client.describeTopic(topicName)
+		if err != nil {
+			return fmt.Errorf("Error while looking for a topic '%s' to adopt: %s", topicName, err)
+		}
+		if existing.exists() {
+			return adoptKafkaTopic(d, meta, existing, conf, ifExists == ifExistsAdoptIfMatching)
+		}
+	}
 
 	d.SetId(topicName)
+	d.Set("full_name", topicName)
 
 	err := client.createTopic(topicName, conf)
 
@@ -83,14 +327,67 @@ func resourceKafkaTopicCreate(d *schema.ResourceData, meta interface{}) error {
 		log.Printf("[DEBUG] Kafka topic '%s:%d:%d' created ", topicName, conf.PartitionsCount, conf.ReplicationFactor)
 	} else {
 		log.Printf("[DEBUG] Kafka - unable to create topic: %v", err)
+		return err
+	}
+	d.Set("created_at", time.Now().UTC().Format(time.RFC3339))
+
+	if err := recordTopicExpiry(d, client); err != nil {
+		return err
+	}
+
+	return finishKafkaTopicChange(d, meta, conf, d.Timeout(schema.TimeoutCreate))
+}
+
+// adoptKafkaTopic takes over an existing topic instead of creating it, then
+// converges it to the declared settings: partitions are added and config
+// overrides applied. A topic whose replication factor differs is refused,
+// as the next plan would replace it and delete its data, and so is one
+// with more partitions than asked for, which Kafka cannot remove. When
+// onlyIfMatching is set, the topic is only adopted if it needs no change
+// at all.
+func adoptKafkaTopic(d *schema.ResourceData, meta interface{}, existing *KafkaTopicInfo, conf *KafkaTopicInfo, onlyIfMatching bool) error {
+	client := meta.(*KafkaManagingClient)
+	topicName := client.TopicNamePolicy.fullName(d.Get("name").(string))
+
+	if existing.ReplicationFactor != conf.ReplicationFactor || existing.PartitionsCount > conf.PartitionsCount ||
+		(onlyIfMatching && existing.PartitionsCount != conf.PartitionsCount) {
+		return fmt.Errorf("Not adopting Kafka topic '%s': it has %d partition(s) and replication factor %d, incompatible with %d partition(s) and replication factor %d",
+			topicName, existing.PartitionsCount, existing.ReplicationFactor, conf.PartitionsCount, conf.ReplicationFactor)
+	}
+
+	mods := diffConfig(conf.Config, existing.Config, topicConfigOwner(d))
+	if onlyIfMatching && !mods.empty() {
+		return fmt.Errorf("Not adopting Kafka topic '%s': its config overrides differ, adding %v and removing %v",
+			topicName, mods.ConfAdditions, mods.ConfDeletions)
+	}
+
+	log.Printf("[INFO] Adopting existing Kafka topic '%s'", topicName)
+	d.SetId(topicName)
+	d.Set("full_name", topicName)
+	d.Set("created_at", time.Now().UTC().Format(time.RFC3339))
+
+	if existing.PartitionsCount < conf.PartitionsCount {
+		if err := client.alterTopicPartitions(topicName, conf.PartitionsCount); err != nil {
+			return err
+		}
+	}
+
+	if !mods.empty() {
+		if err := client.alterTopicConfig(topicName, mods); err != nil {
+			return err
+		}
 	}
 
-	return err
+	if err := recordTopicExpiry(d, client); err != nil {
+		return err
+	}
+
+	return finishKafkaTopicChange(d, meta, conf, d.Timeout(schema.TimeoutCreate))
 }
 
 func resourceKafkaTopicUpdate(d *schema.ResourceData, meta interface{}) error {
-	topicName := d.Get("name").(string)
-	log.Printf("[DEBUG] Kafka topic to update '%s' [%s]", topicName, d.Id())
+	topicName := d.Id()
+	log.Printf("[DEBUG] Kafka topic to update '%s'", topicName)
 
 	client := meta.(*KafkaManagingClient)
 
@@ -100,18 +397,61 @@ func resourceKafkaTopicUpdate(d *schema.ResourceData, meta interface{}) error {
 		}
 	}
 
-	if d.HasChange("cleanup_policy") || d.HasChange("retention_bytes") || d.HasChange("retention_ms") {
-		if ccErr := client.alterTopicConfig(topicName, buildKafkaConfig(d)); ccErr != nil {
+	conf := buildKafkaConfig(d, client.TopicDefaults)
+	actual, _ := d.GetChange("all_config")
+	mods := diffConfig(conf.Config, toStringMap(actual), topicConfigOwner(d))
+
+	if !mods.empty() {
+		if ccErr := client.alterTopicConfig(topicName, mods); ccErr != nil {
 			return ccErr
 		}
 	}
 
-	return nil
+	if d.HasChange("expires_at") || d.HasChange("ttl") {
+		if exErr := recordTopicExpiry(d, client); exErr != nil {
+			return exErr
+		}
+	}
+
+	return finishKafkaTopicChange(d, meta, conf, d.Timeout(schema.TimeoutUpdate))
+}
+
+// finishKafkaTopicChange ends create and update: it waits for Kafka to report
+// the topic as changed, and healthy if require_healthy is set, then reads it
+// back so that state holds what Kafka actually stores.
+func finishKafkaTopicChange(d *schema.ResourceData, meta interface{}, conf *KafkaTopicInfo, timeout time.Duration) error {
+	client := meta.(*KafkaManagingClient)
+	topicName := d.Id()
+	deadline := time.Now().Add(timeout)
+
+	if err := waitForTopic(client, topicName, timeout, conf.satisfiedBy); err != nil {
+		return err
+	}
+
+	if d.Get("unclean_leader_election").(bool) {
+		if err := electTopicLeaders(client, topicName, electionUnclean, deadline); err != nil {
+			return err
+		}
+	}
+
+	if d.Get("elect_preferred_leaders").(bool) {
+		if err := electTopicLeaders(client, topicName, electionPreferred, deadline); err != nil {
+			return err
+		}
+	}
+
+	if d.Get("require_healthy").(bool) {
+		if err := waitForHealthyTopic(client, topicName, deadline.Sub(time.Now())); err != nil {
+			return err
+		}
+	}
+
+	return resourceKafkaTopicRead(d, meta)
 }
 
 func resourceKafkaTopicRead(d *schema.ResourceData, meta interface{}) error {
-	topicName := d.Get("name").(string)
-	log.Printf("[DEBUG] Loading data for Kafka topic '%s' ['%s']", topicName, d.Id())
+	topicName := d.Id()
+	log.Printf("[DEBUG] Loading data for Kafka topic '%s'", topicName)
 
 	client := meta.(*KafkaManagingClient)
 	info, err := client.describeTopic(topicName)
@@ -125,42 +465,583 @@ func resourceKafkaTopicRead(d *schema.ResourceData, meta interface{}) error {
 		return nil
 	}
 
-	d.Set("name", topicName)
+	if d.Get("name").(string) == "" {
+		d.Set("name", strings.TrimPrefix(topicName, client.TopicNamePolicy.Prefix))
+	}
+	d.Set("full_name", topicName)
 	d.Set("partitions", info.PartitionsCount)
 	d.Set("replication_factor", info.ReplicationFactor)
-	d.Set("cleanup_policy", info.CleanupPolicy)
-	d.Set("retention_bytes", info.RetentionBytes)
-	d.Set("retention_ms", info.RetentionMs)
-	d.Set("segment_ms", info.SegmentMs)
-	d.Set("segment_bytes", info.SegmentBytes)
+	readTopicConfigAttrs(d, info, mergeTopicConfig(client.TopicDefaults, ignoredDefaults(d), nil))
+	readUnitAttrs(d, info)
+	d.Set("all_config", info.Config)
+	d.Set("partition", flattenPartitions(info.Partitions))
+
+	if d.Get("expires_at").(string) != "" || d.Get("ttl").(string) != "" {
+		expiresAt, ok, exErr := client.topicExpiry(topicName)
+		if exErr != nil {
+			return fmt.Errorf("Error while reading the expiry of topic '%s': %s", topicName, exErr)
+		}
+		if ok {
+			d.Set("expires_at", expiresAt.Format(time.RFC3339))
+			d.Set("expired", time.Now().After(expiresAt))
+		} else {
+			d.Set("expires_at", "")
+			d.Set("expired", false)
+		}
+	}
+
+	health := info.health()
+	d.Set("under_replicated_partitions", health.UnderReplicated)
+	d.Set("offline_partitions", health.Offline)
+	d.Set("isr_below_min", health.IsrBelowMin)
+
+	conf := make(map[string]string)
+	for k, v := range toStringMap(d.Get("config")) {
+		if sv := info.configStateValue(k, v, ""); sv != "" {
+			conf[k] = sv
+		}
+	}
+	d.Set("config", conf)
+
+	merged := make(map[string]string)
+	for k, v := range toStringMap(d.Get("merged_config")) {
+		if sv := info.configStateValue(k, v, ""); sv != "" {
+			merged[k] = sv
+		}
+	}
+	d.Set("merged_config", merged)
+
+	effective := make(map[string]string)
+	sources := make(map[string]string)
+	for k, e := range info.EffectiveConfig {
+		if !e.Sensitive {
+			effective[k] = e.Value
+		}
+		sources[k] = e.Source
+	}
+	d.Set("effective_config", effective)
+	d.Set("config_sources", sources)
+
+	if d.Get("unmanaged_config_policy").(string) == unmanagedConfigWarn {
+		for k, v := range unmanagedConfig(info.Config, merged) {
+			log.Printf("[WARN] Kafka topic '%s' has unmanaged config override %s=%s", topicName, k, v)
+		}
+	}
 
 	return nil
 }
 
+// resourceKafkaTopicCustomizeDiff runs the plan-time checks on a topic and
+// plans the removal of unmanaged config overrides.
+func resourceKafkaTopicCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if err := checkTopicConfigMap(d); err != nil {
+		return err
+	}
+	if err := checkTopicNamePolicy(d, meta); err != nil {
+		return err
+	}
+	if err := checkTopicNameCollision(d, meta); err != nil {
+		return err
+	}
+	if err := planTopicDefaults(d, meta); err != nil {
+		return err
+	}
+	if err := checkTopicLimits(d, meta); err != nil {
+		return err
+	}
+	if err := planTopicExpiry(d); err != nil {
+		return err
+	}
+	return planUnmanagedConfigRemoval(d)
+}
+
+func checkTopicConfigMap(d *schema.ResourceDiff) error {
+	declared := toStringMap(d.Get("config"))
+	for attr, key := range topicConfigKeys {
+		if _, ok := declared[key]; ok {
+			return fmt.Errorf("config: %s is managed by the %s attribute", key, attr)
+		}
+	}
+	return nil
+}
+
+// checkTopicNamePolicy works out the full name of the topic and, for a new
+// or renamed topic, checks it against the provider's naming policy. A change
+// of topic_name_prefix renames, and so replaces, existing topics. A name
+// only known at apply time leaves full_name unknown, and is checked on
+// create instead.
+func checkTopicNamePolicy(d *schema.ResourceDiff, meta interface{}) error {
+	client, ok := meta.(*KafkaManagingClient)
+	if !ok {
+		return nil
+	}
+	if !d.NewValueKnown("name") {
+		return d.SetNewComputed("full_name")
+	}
+
+	fullName := client.TopicNamePolicy.fullName(d.Get("name").(string))
+	current := d.Get("full_name").(string)
+	if current == "" {
+		// state written before full_name existed, when the ID was the name
+		current = d.Id()
+	}
+	if d.Id() != "" && fullName == current {
+		return nil
+	}
+
+	if err := client.TopicNamePolicy.check(fullName); err != nil {
+		return fmt.Errorf("name: %s", err)
+	}
+	return d.SetNew("full_name", fullName)
+}
+
+// checkTopicNameCollision fails the plan of a new topic whose name would
+// share its metric name with an existing topic, which Kafka would otherwise
+// only report when creating it. A name only known at apply time is checked
+// on create instead.
+func checkTopicNameCollision(d *schema.ResourceDiff, meta interface{}) error {
+	client, ok := meta.(*KafkaManagingClient)
+	if !ok || !d.NewValueKnown("name") || (d.Id() != "" && !d.HasChange("full_name")) {
+		return nil
+	}
+	return checkTopicCollision(client, d.Get("full_name").(string))
+}
+
+// checkTopicCollision fails if another topic shares its metric name with
+// topicName.
+func checkTopicCollision(client *KafkaManagingClient, topicName string) error {
+	if !hasMetricNameRisk(topicName) {
+		return nil
+	}
+
+	topics, err := client.listTopics()
+	if err != nil {
+		return fmt.Errorf("Unable to list topics to check '%s' for collisions: %s", topicName, err)
+	}
+
+	if other := collidingTopic(topicName, topics); other != "" {
+		return fmt.Errorf("name: topic '%s' collides with existing topic '%s' because periods and underscores are the same in metric names", topicName, other)
+	}
+	return nil
+}
+
+// planTopicDefaults shows the provider's default_topic_config in the plan:
+// merged_config gets the config the topic will have, and a new topic not
+// setting replication_factor gets the default one.
+func planTopicDefaults(d *schema.ResourceDiff, meta interface{}) error {
+	var defaults TopicDefaults
+	if client, ok := meta.(*KafkaManagingClient); ok {
+		defaults = client.TopicDefaults
+	}
+
+	if d.Id() == "" && d.Get("replication_factor").(int) == 0 {
+		if ignoredDefaults(d)["replication_factor"] || defaults.ReplicationFactor < 1 {
+			return fmt.Errorf("replication_factor must be set, either on the resource or in the provider's default_topic_config")
+		}
+		if err := d.SetNew("replication_factor", defaults.ReplicationFactor); err != nil {
+			return err
+		}
+	}
+
+	return d.SetNew("merged_config", mergedTopicConfig(d, defaults))
+}
+
+// checkTopicLimits holds the planned topic against the provider's
+// guardrails. It runs after planTopicDefaults so that defaulted settings are
+// checked too.
+func checkTopicLimits(d *schema.ResourceDiff, meta interface{}) error {
+	client, ok := meta.(*KafkaManagingClient)
+	if !ok {
+		return nil
+	}
+	limits := client.TopicLimits
+
+	partitions := d.Get("partitions").(int)
+	retentionMs := toStringMap(d.Get("merged_config"))["retention.ms"]
+	if err := limits.checkTopic(partitions, d.Get("replication_factor").(int), retentionMs); err != nil {
+		return err
+	}
+
+	if limits.MaxTotalPartitions <= 0 || (d.Id() != "" && !d.HasChange("partitions")) {
+		return nil
+	}
+
+	clusterPartitions, err := client.clusterPartitionCount()
+	if err != nil {
+		return fmt.Errorf("Unable to count the cluster's partitions to check max_total_partitions: %s", err)
+	}
+
+	oldPartitions := 0
+	if d.Id() != "" {
+		o, _ := d.GetChange("partitions")
+		oldPartitions = o.(int)
+	}
+	return limits.checkTotalPartitions(clusterPartitions, oldPartitions, partitions)
+}
+
+// planTopicExpiry replaces expired topics, and refuses to create a topic
+// whose expires_at has already passed.
+func planTopicExpiry(d *schema.ResourceDiff) error {
+	expired := d.Get("expired").(bool)
+	if expired {
+		log.Printf("[INFO] Kafka topic '%s' has expired and will be replaced", d.Id())
+		if err := d.SetNew("expired", false); err != nil {
+			return err
+		}
+	}
+
+	if d.Id() != "" && !expired {
+		return nil
+	}
+	if _, ok := d.GetOk("ttl"); ok || !d.NewValueKnown("expires_at") {
+		return nil
+	}
+	if v, ok := d.GetOk("expires_at"); ok {
+		expiresAt, err := time.Parse(time.RFC3339, v.(string))
+		if err == nil && time.Now().After(expiresAt) {
+			return fmt.Errorf("expires_at: %s has already passed, move it forward or remove the topic", v)
+		}
+	}
+	return nil
+}
+
+// planUnmanagedConfigRemoval drops unmanaged overrides from all_config when
+// unmanaged_config_policy is "remove", so that the plan shows them going.
+func planUnmanagedConfigRemoval(d *schema.ResourceDiff) error {
+	if d.Get("unmanaged_config_policy").(string) != unmanagedConfigRemove {
+		return nil
+	}
+
+	current := toStringMap(d.Get("all_config"))
+	unmanaged := unmanagedConfig(current, toStringMap(d.Get("merged_config")))
+	if len(unmanaged) == 0 {
+		return nil
+	}
+
+	kept := make(map[string]string)
+	for k, v := range current {
+		if _, ok := unmanaged[k]; !ok {
+			kept[k] = v
+		}
+	}
+
+	log.Printf("[DEBUG] Kafka topic '%s' will drop unmanaged config overrides %v", d.Get("full_name").(string), unmanaged)
+	return d.SetNew("all_config", kept)
+}
+
+// validateTopicName refuses names Kafka would, and warns about periods and
+// underscores, which may collide in metric names.
+func validateTopicName(v interface{}, k string) ([]string, []error) {
+	name := v.(string)
+	if err := checkTopicName(name); err != nil {
+		return nil, []error{fmt.Errorf("%s: %s", k, err)}
+	}
+	if hasMetricNameRisk(name) {
+		return []string{fmt.Sprintf("%s: '%s' holds periods or underscores, which are the same character in metric names and may collide", k, name)}, nil
+	}
+	return nil, nil
+}
+
+// recordTopicExpiry records when the topic expires: ttl after created_at,
+// or expires_at. A topic with neither has nothing recorded.
+func recordTopicExpiry(d *schema.ResourceData, client *KafkaManagingClient) error {
+	var expiresAt time.Time
+
+	if ttl := d.Get("ttl").(string); ttl != "" {
+		ms, err := parseDuration(ttl)
+		if err != nil {
+			return fmt.Errorf("ttl: %s", err)
+		}
+		createdAt, err := time.Parse(time.RFC3339, d.Get("created_at").(string))
+		if err != nil {
+			// state written before created_at existed
+			createdAt = time.Now()
+			d.Set("created_at", createdAt.UTC().Format(time.RFC3339))
+		}
+		expiresAt = createdAt.Add(time.Duration(ms) * time.Millisecond)
+	} else if v := d.Get("expires_at").(string); v != "" {
+		var err error
+		if expiresAt, err = time.Parse(time.RFC3339, v); err != nil {
+			return fmt.Errorf("expires_at: %s", err)
+		}
+	} else {
+		if d.HasChange("expires_at") || d.HasChange("ttl") {
+			return client.deleteTopicExpiry(d.Id())
+		}
+		return nil
+	}
+
+	log.Printf("[DEBUG] Kafka topic '%s' expires at %s", d.Id(), expiresAt.UTC().Format(time.RFC3339))
+	if err := client.setTopicExpiry(d.Id(), expiresAt); err != nil {
+		return err
+	}
+
+	d.Set("expires_at", expiresAt.UTC().Format(time.RFC3339))
+	d.Set("expired", false)
+	return nil
+}
+
+// electTopicLeaders runs a leader election for the topic unless its leaders
+// are already where the election would put them, then waits for the
+// election to complete: for preferred leaders to lead every partition, or
+// for every partition to have a leader after an unclean election.
+func electTopicLeaders(client *KafkaManagingClient, name string, electionType string, deadline time.Time) error {
+	check := (*KafkaTopicInfo).preferredLeadersElected
+	if electionType == electionUnclean {
+		check = (*KafkaTopicInfo).leadersElected
+	}
+
+	info, err := client.describeTopic(name)
+	if err != nil {
+		return err
+	}
+	if info.exists() {
+		err := check(info)
+		if err == nil {
+			return nil
+		}
+		if _, ok := err.(topicCheckFailure); ok {
+			return fmt.Errorf("Kafka topic '%s': %s", name, err)
+		}
+	}
+
+	log.Printf("[INFO] Running %s leader election for Kafka topic '%s'", strings.ToLower(electionType), name)
+	if err := client.electLeaders(name, electionType); err != nil {
+		return err
+	}
+
+	return waitForTopic(client, name, deadline.Sub(time.Now()), check)
+}
+
+// waitForTopic polls the topic until check passes, tolerating the delay
+// before a change shows in the metadata, and fails once timeout expires.
+func waitForTopic(client *KafkaManagingClient, name string, timeout time.Duration, check func(*KafkaTopicInfo) error) error {
+	return resource.Retry(timeout, func() *resource.RetryError {
+		info, err := client.describeTopic(name)
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		if !info.exists() {
+			return resource.RetryableError(fmt.Errorf("Kafka topic '%s' not found", name))
+		}
+		if err := check(info); err != nil {
+			if _, ok := err.(topicCheckFailure); ok {
+				return resource.NonRetryableError(fmt.Errorf("Kafka topic '%s': %s", name, err))
+			}
+			return resource.RetryableError(fmt.Errorf("Kafka topic '%s': %s", name, err))
+		}
+		return nil
+	})
+}
+
+// waitForHealthyTopic polls the topic until it is healthy, giving new
+// replicas a chance to join the ISR, and fails once timeout expires.
+func waitForHealthyTopic(client *KafkaManagingClient, name string, timeout time.Duration) error {
+	return waitForTopic(client, name, timeout, func(info *KafkaTopicInfo) error {
+		if h := info.health(); !h.healthy() {
+			return fmt.Errorf("not healthy: %s", h)
+		}
+		return nil
+	})
+}
+
 func resourceKafkaTopicDelete(d *schema.ResourceData, meta interface{}) error {
-	topicName := d.Get("name").(string)
-	log.Printf("[DEBUG] Kafka to delete topic '%s' [%s]", topicName, d.Id())
+	topicName := d.Id()
 
 	client := meta.(*KafkaManagingClient)
+	hasExpiry := d.Get("expires_at").(string) != ""
 
-	return client.deleteTopic(topicName)
+	if d.Get("retain_on_destroy").(bool) {
+		log.Printf("[WARN] Kafka topic '%s' has retain_on_destroy set: removing it from state and leaving the topic in place", topicName)
+		// A retained topic is no longer ephemeral: forget its expiry so
+		// that cleanup does not pick it up.
+		if hasExpiry {
+			if err := client.deleteTopicExpiry(topicName); err != nil {
+				return err
+			}
+		}
+		d.SetId("")
+		return nil
+	}
+
+	log.Printf("[DEBUG] Kafka to delete topic '%s'", topicName)
+
+	if err := client.deleteTopic(topicName); err != nil {
+		return err
+	}
+
+	if hasExpiry {
+		return client.deleteTopicExpiry(topicName)
+	}
+	return nil
 }
 
-func buildKafkaConfig(d *schema.ResourceData) *KafkaTopicInfo {
+// resourceGetter is what *schema.ResourceData and *schema.ResourceDiff have in
+// common for reading a resource's settings.
+type resourceGetter interface {
+	Get(key string) interface{}
+}
+
+func buildKafkaConfig(d *schema.ResourceData, defaults TopicDefaults) *KafkaTopicInfo {
+	replicationFactor := d.Get("replication_factor").(int)
+	if replicationFactor == 0 && !ignoredDefaults(d)["replication_factor"] {
+		replicationFactor = defaults.ReplicationFactor
+	}
+
 	return &KafkaTopicInfo{
-		PartitionsCount:          d.Get("partitions").(int),
-		ReplicationFactor:        d.Get("replication_factor").(int),
-		CleanupPolicy:            d.Get("cleanup_policy").(string),
-		RetentionBytes:           int64(d.Get("retention_bytes").(int)),
-		RetentionMs:              int64(d.Get("retention_ms").(int)),
-		SegmentBytes:             int64(d.Get("segment_bytes").(int)),
-		SegmentMs:                int64(d.Get("segment_ms").(int)),
-		PartitionsCountChanged:   d.HasChange("partitions"),
-		ReplicationFactorChanged: d.HasChange("replication_factor"),
-		CleanupPolicyChanged:     d.HasChange("cleanup_policy"),
-		RetentionBytesChanged:    d.HasChange("retention_bytes"),
-		RetentionMsChanged:       d.HasChange("retention_ms"),
-		SegmentBytesChanged:      d.HasChange("segment_bytes"),
-		SegmentMsChanged:         d.HasChange("segment_ms"),
+		PartitionsCount:   d.Get("partitions").(int),
+		ReplicationFactor: replicationFactor,
+		Config:            mergedTopicConfig(d, defaults),
+	}
+}
+
+// mergedTopicConfig returns the overrides to apply to the topic: the
+// resource's own laid over the provider's default config.
+func mergedTopicConfig(d resourceGetter, defaults TopicDefaults) map[string]string {
+	return mergeTopicConfig(defaults, ignoredDefaults(d), desiredTopicConfig(d))
+}
+
+func ignoredDefaults(d resourceGetter) map[string]bool {
+	ignored := make(map[string]bool)
+	if set, ok := d.Get("ignore_defaults").(*schema.Set); ok {
+		for _, k := range set.List() {
+			ignored[k.(string)] = true
+		}
+	}
+	return ignored
+}
+
+// desiredTopicConfig collects the overrides declared on the resource, from
+// both the typed attributes and the config map. Typed attributes left at
+// their default are not overrides, while -1 is sent as is.
+func desiredTopicConfig(d resourceGetter) map[string]string {
+	conf := toStringMap(d.Get("config"))
+	for attr, key := range topicConfigKeys {
+		switch v := d.Get(attr).(type) {
+		case int:
+			if v != topicConfigUnset {
+				conf[key] = strconv.Itoa(v)
+			}
+		case string:
+			if v != "" {
+				conf[key] = v
+			}
+		}
+	}
+	for attr, u := range topicConfigUnits {
+		if v, err := u.parse(d.Get(attr).(string)); err == nil {
+			conf[topicConfigKeys[u.numeric]] = strconv.FormatInt(v, 10)
+		}
+	}
+	return conf
+}
+
+// readTopicConfigAttrs sets the typed config attributes from what was read.
+// An unset attribute stays unset when the override matches the provider's
+// default for it, as it is the default that asked for the override.
+func readTopicConfigAttrs(d *schema.ResourceData, info *KafkaTopicInfo, defaults map[string]string) {
+	fromDefaults := func(key string) bool {
+		v, ok := defaults[key]
+		return ok && info.Config[key] == v
+	}
+
+	for attr, key := range topicConfigKeys {
+		switch current := d.Get(attr).(type) {
+		case int:
+			if _, ok := info.Config[key]; current == -1 && !ok {
+				// state written when -1 stood for unset
+				current = topicConfigUnset
+			}
+			if current != topicConfigUnset || !fromDefaults(key) {
+				d.Set(attr, info.configStateInt(key, current))
+			}
+		case string:
+			if current != "" || !fromDefaults(key) {
+				d.Set(attr, info.configStateValue(key, current, ""))
+			}
+		}
+	}
+}
+
+// readUnitAttrs moves the values read for numeric attributes over to their
+// human-readable counterparts when those are the ones in use, leaving the
+// numeric attributes unset.
+func readUnitAttrs(d *schema.ResourceData, info *KafkaTopicInfo) {
+	for attr, u := range topicConfigUnits {
+		current, err := u.parse(d.Get(attr).(string))
+		if err != nil {
+			continue
+		}
+
+		v := info.configStateValue(topicConfigKeys[u.numeric], strconv.FormatInt(current, 10), "")
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			d.Set(attr, u.format(n))
+		} else {
+			d.Set(attr, "")
+		}
+		d.Set(u.numeric, topicConfigUnset)
+	}
+}
+
+func validateUnitAttr(parse func(string) (int64, error)) schema.SchemaValidateFunc {
+	return func(v interface{}, k string) ([]string, []error) {
+		if _, err := parse(v.(string)); err != nil {
+			return nil, []error{fmt.Errorf("%s: %s", k, err)}
+		}
+		return nil, nil
+	}
+}
+
+// canonicalUnitAttr stores human-readable values in the form Read writes
+// them back in, so that "1024MiB" and "1GiB" do not differ.
+func canonicalUnitAttr(parse func(string) (int64, error), format func(int64) string) schema.SchemaStateFunc {
+	return func(v interface{}) string {
+		s := v.(string)
+		if n, err := parse(s); err == nil {
+			return format(n)
+		}
+		return s
+	}
+}
+
+// topicConfigOwner tells which existing overrides the resource may delete:
+// the ones backed by an attribute, the ones declared in the config map or
+// coming from the provider's defaults now or before this change, and any
+// other but replication throttles if unmanaged_config_policy is "remove".
+func topicConfigOwner(d *schema.ResourceData) func(key string) bool {
+	o, n := d.GetChange("config")
+	oldDeclared, newDeclared := toStringMap(o), toStringMap(n)
+	o, _ = d.GetChange("merged_config")
+	oldMerged := toStringMap(o)
+	removeUnmanaged := d.Get("unmanaged_config_policy").(string) == unmanagedConfigRemove
+
+	return func(key string) bool {
+		_, wasDeclared := oldDeclared[key]
+		_, isDeclared := newDeclared[key]
+		_, wasMerged := oldMerged[key]
+		return isManagedConfigKey(key) || wasDeclared || isDeclared || wasMerged || (removeUnmanaged && !isThrottleConfigKey(key))
+	}
+}
+
+func flattenPartitions(partitions []PartitionInfo) []interface{} {
+	result := make([]interface{}, 0, len(partitions))
+	for _, p := range partitions {
+		result = append(result, map[string]interface{}{
+			"id":       p.ID,
+			"leader":   p.Leader,
+			"replicas": p.Replicas,
+			"isr":      p.Isr,
+			"offline":  p.offline(),
+		})
+	}
+	return result
+}
+
+func toStringMap(v interface{}) map[string]string {
+	m := make(map[string]string)
+	raw, _ := v.(map[string]interface{})
+	for k, e := range raw {
+		m[k] = fmt.Sprintf("%v", e)
 	}
+	return m
 }
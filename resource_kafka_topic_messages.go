@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceKafkaTopicMessages() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKafkaTopicMessagesCreate,
+		Read:   resourceKafkaTopicMessagesRead,
+		Update: resourceKafkaTopicMessagesUpdate,
+		Delete: resourceKafkaTopicMessagesDelete,
+
+		CustomizeDiff: resourceKafkaTopicMessagesCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"topic": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "topic to write the records to, usually a compacted one",
+			},
+			"record": &schema.Schema{
+				Type:        schema.TypeList,
+				Required:    true,
+				Description: "records to write, one per key",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"value": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"headers": &schema.Schema{
+							Type:     schema.TypeMap,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"tombstone_on_destroy": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "write tombstones for every key on destroy",
+				Default:     true,
+			},
+		},
+	}
+}
+
+func resourceKafkaTopicMessagesCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+	topic := d.Get("topic").(string)
+
+	records := recordChanges(map[string]TopicRecord{}, expandTopicRecords(d.Get("record")))
+	log.Printf("[INFO] Writing %d record(s) to Kafka topic '%s'", len(records), topic)
+	if err := client.produceRecords(topic, records); err != nil {
+		return err
+	}
+
+	d.SetId(topic)
+	return resourceKafkaTopicMessagesRead(d, meta)
+}
+
+// resourceKafkaTopicMessagesUpdate writes the records that are new or
+// changed, and tombstones the keys that are gone.
+func resourceKafkaTopicMessagesUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+
+	err := updateTopicRecords(d, func(records []TopicRecord) error {
+		log.Printf("[INFO] Writing %d record(s) to Kafka topic '%s'", len(records), d.Id())
+		return client.produceRecords(d.Id(), records)
+	})
+	if err != nil {
+		return err
+	}
+
+	return resourceKafkaTopicMessagesRead(d, meta)
+}
+
+// partialResource is the part of *schema.ResourceData updateTopicRecords
+// needs.
+type partialResource interface {
+	GetChange(key string) (interface{}, interface{})
+	Partial(on bool)
+	SetPartial(key string)
+}
+
+// updateTopicRecords writes the record changes with produce. The new
+// record list only reaches state once they are written: records are never
+// read back, so saving it after a failed write would hide the changes from
+// the next plan.
+func updateTopicRecords(d partialResource, produce func(records []TopicRecord) error) error {
+	o, n := d.GetChange("record")
+	records := recordChanges(expandTopicRecords(o), expandTopicRecords(n))
+
+	d.Partial(true)
+	if len(records) > 0 {
+		if err := produce(records); err != nil {
+			return err
+		}
+	}
+	d.SetPartial("record")
+	d.Partial(false)
+	return nil
+}
+
+// resourceKafkaTopicMessagesRead only checks the topic still exists: the
+// records are not read back, which would mean consuming the whole topic.
+func resourceKafkaTopicMessagesRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+
+	info, err := client.describeTopic(d.Id())
+	if err != nil {
+		return err
+	}
+	if !info.exists() {
+		log.Printf("[WARN] Kafka topic '%s' not found, removing its records from state", d.Id())
+		d.SetId("")
+	}
+	return nil
+}
+
+func resourceKafkaTopicMessagesDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+
+	if d.Get("tombstone_on_destroy").(bool) {
+		records := recordChanges(expandTopicRecords(d.Get("record")), map[string]TopicRecord{})
+		log.Printf("[INFO] Writing %d tombstone(s) to Kafka topic '%s'", len(records), d.Id())
+		if err := client.produceRecords(d.Id(), records); err != nil {
+			return err
+		}
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// resourceKafkaTopicMessagesCustomizeDiff refuses keys listed twice and
+// text the console producer would take for separators.
+func resourceKafkaTopicMessagesCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	seen := make(map[string]bool)
+	for _, raw := range d.Get("record").([]interface{}) {
+		r := raw.(map[string]interface{})
+		key := r["key"].(string)
+		if seen[key] {
+			return fmt.Errorf("Key '%s' is listed more than once", key)
+		}
+		seen[key] = true
+
+		if err := checkRecordText("key", key, ""); err != nil {
+			return err
+		}
+		if err := checkRecordText("value", r["value"].(string), ""); err != nil {
+			return err
+		}
+		for k, v := range toStringMap(r["headers"]) {
+			if err := checkRecordText("header", k, ",:"); err != nil {
+				return err
+			}
+			if err := checkRecordText("header value", v, ","); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func expandTopicRecords(v interface{}) map[string]TopicRecord {
+	records := make(map[string]TopicRecord)
+	for _, raw := range v.([]interface{}) {
+		r := raw.(map[string]interface{})
+		key := r["key"].(string)
+		records[key] = TopicRecord{
+			Key:     key,
+			Value:   r["value"].(string),
+			Headers: toStringMap(r["headers"]),
+		}
+	}
+	return records
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func resourceKafkaUserScramCredential() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKafkaUserScramCredentialCreate,
+		Read:   resourceKafkaUserScramCredentialRead,
+		Update: resourceKafkaUserScramCredentialUpdate,
+		Delete: resourceKafkaUserScramCredentialDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceKafkaUserScramCredentialImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"username": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "name of the user",
+			},
+			"mechanism": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "SCRAM-SHA-256 or SCRAM-SHA-512",
+				ValidateFunc: validation.StringInSlice(scramMechanisms, false),
+			},
+			"password": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				Sensitive:    true,
+				Description:  "password of the user; changing it rotates the credential",
+				ValidateFunc: validateScramPassword,
+			},
+			"iterations": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "iterations of the salted password hash",
+				Default:      scramMinIterations,
+				ValidateFunc: validation.IntBetween(scramMinIterations, scramMaxIterations),
+			},
+		},
+	}
+}
+
+func resourceKafkaUserScramCredentialCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+	user := d.Get("username").(string)
+	mechanism := d.Get("mechanism").(string)
+
+	credentials, err := client.scramCredentials(user)
+	if err != nil {
+		return err
+	}
+	if _, ok := credentials[mechanism]; ok {
+		return fmt.Errorf("User '%s' already has a %s credential; import it instead", user, mechanism)
+	}
+
+	if err := client.alterScramCredential(user, mechanism, d.Get("iterations").(int), d.Get("password").(string)); err != nil {
+		return err
+	}
+
+	d.SetId(user + "|" + mechanism)
+	return resourceKafkaUserScramCredentialRead(d, meta)
+}
+
+func resourceKafkaUserScramCredentialUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+
+	if d.HasChange("password") || d.HasChange("iterations") {
+		log.Printf("[INFO] Rotating %s credential of user '%s'", d.Get("mechanism"), d.Get("username"))
+		if err := client.alterScramCredential(d.Get("username").(string), d.Get("mechanism").(string), d.Get("iterations").(int), d.Get("password").(string)); err != nil {
+			return err
+		}
+	}
+
+	return resourceKafkaUserScramCredentialRead(d, meta)
+}
+
+// resourceKafkaUserScramCredentialRead can only check the credential exists
+// and read its iterations: Kafka keeps a salted hash, not the password.
+func resourceKafkaUserScramCredentialRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+	user, mechanism, err := parseScramCredentialID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	credentials, err := client.scramCredentials(user)
+	if err != nil {
+		return err
+	}
+
+	iterations, ok := credentials[mechanism]
+	if !ok {
+		log.Printf("[WARN] User '%s' has no %s credential, removing from state", user, mechanism)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("username", user)
+	d.Set("mechanism", mechanism)
+	d.Set("iterations", iterations)
+	return nil
+}
+
+func resourceKafkaUserScramCredentialDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*KafkaManagingClient)
+	user, mechanism, err := parseScramCredentialID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	if err := client.deleteScramCredential(user, mechanism); err != nil {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// resourceKafkaUserScramCredentialImport takes an ID of the form
+// username|mechanism. The password stays unknown, so the next apply rotates
+// the credential to the configured one.
+func resourceKafkaUserScramCredentialImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if _, _, err := parseScramCredentialID(d.Id()); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+func parseScramCredentialID(id string) (string, string, error) {
+	i := strings.LastIndex(id, "|")
+	if i <= 0 {
+		return "", "", fmt.Errorf("Invalid SCRAM credential ID '%s', expected username|mechanism", id)
+	}
+	return id[:i], id[i+1:], nil
+}
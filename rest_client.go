@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// restClient calls the JSON REST APIs living next to Kafka, such as the
+// Schema Registry's or Kafka Connect's.
+type restClient struct {
+	URL         string
+	Username    string
+	Password    string
+	ContentType string
+	HTTP        *http.Client
+}
+
+func newRestClient(baseURL string, username string, password string, contentType string) restClient {
+	return restClient{
+		URL:         strings.TrimRight(baseURL, "/"),
+		Username:    username,
+		Password:    password,
+		ContentType: contentType,
+		HTTP:        &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// RestAPIError is an error a REST API answered with. Both the Schema
+// Registry and Kafka Connect describe errors with an error_code and a
+// message.
+type RestAPIError struct {
+	StatusCode int
+	ErrorCode  int    `json:"error_code"`
+	Message    string `json:"message"`
+}
+
+func (e *RestAPIError) Error() string {
+	return fmt.Sprintf("Error %d: %s", e.ErrorCode, e.Message)
+}
+
+func isRestAPIStatus(err error, status int) bool {
+	rErr, ok := err.(*RestAPIError)
+	return ok && rErr.StatusCode == status
+}
+
+func isRestAPINotFound(err error) bool {
+	return isRestAPIStatus(err, http.StatusNotFound)
+}
+
+// do sends body as JSON, if not nil, and decodes the answer into result, if
+// not nil.
+func (client *restClient) do(method string, path string, body interface{}, result interface{}) error {
+	reader := bytes.NewReader(nil)
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, client.URL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", client.ContentType)
+	if body != nil {
+		req.Header.Set("Content-Type", client.ContentType)
+	}
+	if client.Username != "" {
+		req.SetBasicAuth(client.Username, client.Password)
+	}
+
+	log.Printf("[DEBUG] Request %s %s", method, client.URL+path)
+	resp, err := client.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		rErr := &RestAPIError{StatusCode: resp.StatusCode, ErrorCode: resp.StatusCode}
+		if json.Unmarshal(data, rErr) != nil || rErr.Message == "" {
+			rErr.Message = fmt.Sprintf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+		}
+		return rErr
+	}
+
+	if result == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
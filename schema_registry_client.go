@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+const schemaRegistryContentType = "application/vnd.schemaregistry.v1+json"
+
+// Schema types the registry knows. AVRO is the default, and the only one
+// registries older than Confluent 5.5 support.
+const (
+	schemaTypeAvro     = "AVRO"
+	schemaTypeJSON     = "JSON"
+	schemaTypeProtobuf = "PROTOBUF"
+)
+
+var compatibilityLevels = []string{
+	"NONE", "BACKWARD", "BACKWARD_TRANSITIVE", "FORWARD", "FORWARD_TRANSITIVE", "FULL", "FULL_TRANSITIVE",
+}
+
+// SchemaRegistryClient talks to a Confluent Schema Registry over its REST
+// API.
+type SchemaRegistryClient struct {
+	restClient
+}
+
+func newSchemaRegistryClient(registryURL string, username string, password string) *SchemaRegistryClient {
+	return &SchemaRegistryClient{newRestClient(registryURL, username, password, schemaRegistryContentType)}
+}
+
+// SubjectSchema is a version of the schema registered under a subject.
+type SubjectSchema struct {
+	Subject    string `json:"subject"`
+	Version    int    `json:"version"`
+	ID         int    `json:"id"`
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType,omitempty"`
+}
+
+// registerSchema registers a schema under a subject, returning its id.
+// Registering a schema the subject already has returns the existing id.
+func (client *SchemaRegistryClient) registerSchema(subject string, schemaType string, schema string) (int, error) {
+	body := map[string]string{"schema": schema}
+	if schemaType != "" && schemaType != schemaTypeAvro {
+		body["schemaType"] = schemaType
+	}
+
+	var result struct {
+		ID int `json:"id"`
+	}
+	err := client.do("POST", "/subjects/"+url.PathEscape(subject)+"/versions", body, &result)
+	return result.ID, err
+}
+
+func (client *SchemaRegistryClient) latestSchema(subject string) (*SubjectSchema, error) {
+	var result SubjectSchema
+	if err := client.do("GET", "/subjects/"+url.PathEscape(subject)+"/versions/latest", nil, &result); err != nil {
+		return nil, err
+	}
+	if result.SchemaType == "" {
+		result.SchemaType = schemaTypeAvro
+	}
+	return &result, nil
+}
+
+// deleteSubject deletes every version of a subject, permanently if asked
+// to, which the registry only allows once they are soft-deleted.
+func (client *SchemaRegistryClient) deleteSubject(subject string, permanent bool) error {
+	path := "/subjects/" + url.PathEscape(subject)
+	if err := client.do("DELETE", path, nil, nil); err != nil {
+		return err
+	}
+	if permanent {
+		return client.do("DELETE", path+"?permanent=true", nil, nil)
+	}
+	return nil
+}
+
+// compatibility returns the compatibility level set on a subject, or ""
+// when it follows the global one.
+func (client *SchemaRegistryClient) compatibility(subject string) (string, error) {
+	var result struct {
+		CompatibilityLevel string `json:"compatibilityLevel"`
+	}
+	err := client.do("GET", "/config/"+url.PathEscape(subject), nil, &result)
+	if isRestAPINotFound(err) {
+		return "", nil
+	}
+	return result.CompatibilityLevel, err
+}
+
+func (client *SchemaRegistryClient) setCompatibility(subject string, level string) error {
+	return client.do("PUT", "/config/"+url.PathEscape(subject), map[string]string{"compatibility": level}, nil)
+}
+
+// resetCompatibility makes a subject follow the global compatibility level
+// again.
+func (client *SchemaRegistryClient) resetCompatibility(subject string) error {
+	err := client.do("DELETE", "/config/"+url.PathEscape(subject), nil, nil)
+	if isRestAPINotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// topicNameStrategySubject names the subject of a topic's keys or values
+// the way the serializers' default TopicNameStrategy does.
+func topicNameStrategySubject(topic string, isKey bool) string {
+	if isKey {
+		return topic + "-key"
+	}
+	return topic + "-value"
+}
+
+// sameSchema compares schemas ignoring the formatting the registry drops,
+// by comparing JSON schemas and Avro ones, which are JSON too, once parsed.
+func sameSchema(schemaType string, a string, b string) bool {
+	if schemaType == schemaTypeProtobuf {
+		return strings.TrimSpace(a) == strings.TrimSpace(b)
+	}
+
+	var av, bv interface{}
+	if json.Unmarshal([]byte(a), &av) != nil || json.Unmarshal([]byte(b), &bv) != nil {
+		return strings.TrimSpace(a) == strings.TrimSpace(b)
+	}
+	an, _ := json.Marshal(av)
+	bn, _ := json.Marshal(bv)
+	return string(an) == string(bn)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeSchemaRegistry is a stand-in for the parts of the Schema Registry API
+// the provider uses, keeping the versions of each subject in memory.
+type fakeSchemaRegistry struct {
+	versions      map[string][]SubjectSchema
+	compatibility map[string]string
+	nextID        int
+}
+
+func newFakeSchemaRegistry() *fakeSchemaRegistry {
+	return &fakeSchemaRegistry{
+		versions:      make(map[string][]SubjectSchema),
+		compatibility: make(map[string]string),
+		nextID:        1,
+	}
+}
+
+func (r *fakeSchemaRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if user, password, _ := req.BasicAuth(); user != "registry" || password != "secret" {
+		writeRegistryError(w, http.StatusUnauthorized, 40101, "Unauthorized")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	switch {
+	case parts[0] == "subjects" && len(parts) == 3 && req.Method == "POST":
+		var body SubjectSchema
+		json.NewDecoder(req.Body).Decode(&body)
+		for _, v := range r.versions[parts[1]] {
+			if v.Schema == body.Schema {
+				json.NewEncoder(w).Encode(map[string]int{"id": v.ID})
+				return
+			}
+		}
+		s := SubjectSchema{Subject: parts[1], Version: len(r.versions[parts[1]]) + 1, ID: r.nextID, Schema: body.Schema, SchemaType: body.SchemaType}
+		r.nextID++
+		r.versions[parts[1]] = append(r.versions[parts[1]], s)
+		json.NewEncoder(w).Encode(map[string]int{"id": s.ID})
+
+	case parts[0] == "subjects" && len(parts) == 4 && req.Method == "GET":
+		versions := r.versions[parts[1]]
+		if len(versions) == 0 {
+			writeRegistryError(w, http.StatusNotFound, 40401, "Subject not found.")
+			return
+		}
+		json.NewEncoder(w).Encode(versions[len(versions)-1])
+
+	case parts[0] == "subjects" && len(parts) == 2 && req.Method == "DELETE":
+		if req.URL.Query().Get("permanent") == "true" {
+			delete(r.versions, parts[1])
+		}
+		w.Write([]byte("[1]"))
+
+	case parts[0] == "config" && req.Method == "PUT":
+		var body map[string]string
+		json.NewDecoder(req.Body).Decode(&body)
+		r.compatibility[parts[1]] = body["compatibility"]
+		json.NewEncoder(w).Encode(body)
+
+	case parts[0] == "config" && req.Method == "GET":
+		level, ok := r.compatibility[parts[1]]
+		if !ok {
+			writeRegistryError(w, http.StatusNotFound, 40408, "Subject does not have subject-level compatibility configured")
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"compatibilityLevel": level})
+
+	case parts[0] == "config" && req.Method == "DELETE":
+		delete(r.compatibility, parts[1])
+		w.Write([]byte("{}"))
+
+	default:
+		writeRegistryError(w, http.StatusNotFound, 404, "Not found")
+	}
+}
+
+func writeRegistryError(w http.ResponseWriter, status int, code int, message string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"error_code": code, "message": message})
+}
+
+func TestSchemaRegistryClient(t *testing.T) {
+	server := httptest.NewServer(newFakeSchemaRegistry())
+	defer server.Close()
+	client := newSchemaRegistryClient(server.URL+"/", "registry", "secret")
+	subject := topicNameStrategySubject("orders", false)
+
+	if _, err := client.latestSchema(subject); !isRestAPINotFound(err) {
+		t.Fatalf("expected subject not found, got %v", err)
+	}
+
+	v1 := `{"type": "record", "name": "Order", "fields": [{"name": "id", "type": "string"}]}`
+	id, err := client.registerSchema(subject, schemaTypeAvro, v1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := client.registerSchema(subject, schemaTypeAvro, v1); again != id {
+		t.Errorf("expected registering the same schema to return id %d, got %d", id, again)
+	}
+
+	latest, err := client.latestSchema(subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Subject != "orders-value" || latest.Version != 1 || latest.ID != id || latest.SchemaType != schemaTypeAvro {
+		t.Errorf("unexpected latest schema %+v", latest)
+	}
+
+	if level, err := client.compatibility(subject); err != nil || level != "" {
+		t.Errorf("expected no compatibility level, got '%s' %v", level, err)
+	}
+	if err := client.setCompatibility(subject, "FULL"); err != nil {
+		t.Fatal(err)
+	}
+	if level, err := client.compatibility(subject); err != nil || level != "FULL" {
+		t.Errorf("expected FULL, got '%s' %v", level, err)
+	}
+
+	if err := client.deleteSubject(subject, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.latestSchema(subject); !isRestAPINotFound(err) {
+		t.Errorf("expected subject to be deleted, got %v", err)
+	}
+
+	client.Password = "wrong"
+	if _, err := client.latestSchema(subject); err == nil || !strings.Contains(err.Error(), "40101") {
+		t.Errorf("expected an authentication error, got %v", err)
+	}
+}
+
+func TestSameSchema(t *testing.T) {
+	if !sameSchema(schemaTypeAvro, `{"type": "string"}`, "{\"type\":\"string\"}\n") {
+		t.Errorf("expected formatting to be ignored")
+	}
+	if sameSchema(schemaTypeJSON, `{"type": "string"}`, `{"type": "integer"}`) {
+		t.Errorf("expected different schemas to differ")
+	}
+	if !sameSchema(schemaTypeProtobuf, "syntax = \"proto3\";\n", "syntax = \"proto3\";") {
+		t.Errorf("expected surrounding whitespace to be ignored")
+	}
+}